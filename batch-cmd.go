@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/repo"
+)
+
+// Batch report status of each identifier
+const (
+	batchMatched   = "matched"
+	batchAmbiguous = "ambiguous"
+	batchMissing   = "missing"
+	batchFailed    = "failed"
+)
+
+var doiRe = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+
+// batchEntry is a line of the batch file and its outcome
+type batchEntry struct {
+	identifier string
+	status     string
+	title      string
+	author     string
+	path       string
+	note       string
+}
+
+// readIdentifiers reads one identifier per line. CSV files use the first column,
+// skipping a header row whose first cell isn't an ISBN or DOI
+func readIdentifiers(fp string) ([]string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ids := []string{}
+	if strings.EqualFold(filepath.Ext(fp), ".csv") {
+		reader := csv.NewReader(f)
+		reader.FieldsPerRecord = -1
+		for first := true; ; first = false {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(record) == 0 {
+				continue
+			}
+			id := strings.TrimSpace(record[0])
+			if first && !isIdentifier(id) {
+				continue
+			}
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			ids = append(ids, line)
+		}
+	}
+	return ids, scanner.Err()
+}
+
+// isIdentifier reports whether s is an ISBN or a DOI
+func isIdentifier(s string) bool {
+	_, err := book.ParseISBN(s)
+	return err == nil || doiRe.MatchString(s)
+}
+
+// termQuery searches ISBNs and DOIs in the identifier column and anything else as a title.
+// ISBNs are searched without hyphens
+func termQuery(r repo.Repository, identifier string) *repo.QueryOptions {
+	if isbn, err := book.ParseISBN(identifier); err == nil {
+		q := repo.NewQueryOptions(isbn.String())
+		q.Field = r.IdentifierColumn()
+		return q
+	}
+	if doiRe.MatchString(identifier) {
+		q := repo.NewQueryOptions(identifier)
+		q.Field = r.IdentifierColumn()
+		return q
+	}
+	return repo.NewQueryOptions(identifier)
+}
+
+func matchRules() *repo.MatchRules {
+	m := config.UserConfig.Match
+	return &repo.MatchRules{Extensions: m.Extensions, Language: m.Language, Prefer: m.Prefer}
+}
+
+// batchOne searches an identifier and downloads the best match
+func batchOne(r repo.Repository, rules *repo.MatchRules, identifier string, force bool) *batchEntry {
+	entry := &batchEntry{identifier: identifier}
+	rows, _, err := fetchBookRows(r, termQuery(r, identifier), repo.RowStep)
+	if err != nil && err != repo.NoRowsError {
+		entry.status = batchFailed
+		entry.note = err.Error()
+		return entry
+	}
+	rows = searchFilter.Apply(r, rows)
+	if len(rows) == 0 {
+		entry.status = batchMissing
+		return entry
+	}
+	best, ties := rules.Best(r, rows)
+	entry.title = best.Column(r, repo.TitleColumn)
+	entry.author = best.Column(r, repo.AuthorColumn)
+	if len(ties) > 0 && !force {
+		entry.status = batchAmbiguous
+		entry.note = fmt.Sprintf("%d equally good results", len(ties)+1)
+		return entry
+	}
+	b, err := bookOf(r, best)
+	if err != nil {
+		entry.note = "book info not available: " + err.Error()
+	}
+	if entry.path, err = downloadNow(r, b, config.UserConfig.Mirror); err != nil {
+		entry.status = batchFailed
+		entry.note = err.Error()
+		return entry
+	}
+	entry.status = batchMatched
+	return entry
+}
+
+func writeBatchReport(fp string, entries []*batchEntry) error {
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	writer := csv.NewWriter(f)
+	writer.Write([]string{"status", "identifier", "title", "author", "path", "note"})
+	for _, e := range entries {
+		writer.Write([]string{e.status, e.identifier, e.title, e.author, e.path, e.note})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// batchCmd usage: batch [-o report.csv] [-force] <file>
+func batchCmd(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	report := flags.String("o", "", "report file path. Default: <file>.report.csv")
+	force := flags.Bool("force", false, "download the first best match of ambiguous identifiers")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("batch: usage: batch [-o report.csv] [-force] <file>")
+	}
+	fp := flags.Arg(0)
+	if *report == "" {
+		*report = strings.TrimSuffix(fp, filepath.Ext(fp)) + ".report.csv"
+	}
+	ids, err := readIdentifiers(fp)
+	if err != nil {
+		return err
+	}
+	r := reposToSearch()
+	rules := matchRules()
+	entries := make([]*batchEntry, len(ids))
+	count := map[string]int{}
+	for i, id := range ids {
+		entries[i] = batchOne(r, rules, id, *force)
+		count[entries[i].status]++
+		log.Printf("[%d/%d] %s: %s %s", i+1, len(ids), entries[i].status, id, entries[i].note)
+	}
+	if err := writeBatchReport(*report, entries); err != nil {
+		return err
+	}
+	fmt.Printf("%d matched, %d ambiguous, %d missing, %d failed. Report: %s\n",
+		count[batchMatched], count[batchAmbiguous], count[batchMissing], count[batchFailed], *report)
+	return nil
+}
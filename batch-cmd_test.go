@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadIdentifiersCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"isbn,title\n0131103628,The C Programming Language\n10.1000/182,DOI\n", []string{"0131103628", "10.1000/182"}},
+		{"0131103628,The C Programming Language\nConcrete Mathematics\n", []string{"0131103628", "Concrete Mathematics"}},
+		{"10.1000/182\n", []string{"10.1000/182"}},
+		{"", []string{}},
+	}
+	for i, tt := range tests {
+		fp := filepath.Join(dir, "list.csv")
+		if err := ioutil.WriteFile(fp, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readIdentifiers(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d: readIdentifiers = %q, want %q", i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package book
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// BibEntry is an entry read from a BibTeX file. Field names are lowercase
+type BibEntry struct {
+	Type   string
+	Key    string
+	Fields map[string]string
+}
+
+// ParseBibTeX reads the entries of a BibTeX file, expanding @string macros in their fields.
+// @comment and @preamble are skipped
+func ParseBibTeX(data string) []BibEntry {
+	entries := []BibEntry{}
+	macros := map[string]string{}
+	for i := 0; i < len(data); i++ {
+		if data[i] != '@' {
+			continue
+		}
+		j := i + 1
+		for j < len(data) && (unicode.IsLetter(rune(data[j]))) {
+			j++
+		}
+		typ := strings.ToLower(data[i+1 : j])
+		for j < len(data) && unicode.IsSpace(rune(data[j])) {
+			j++
+		}
+		if typ == "" || j >= len(data) || (data[j] != '{' && data[j] != '(') {
+			continue
+		}
+		end := closing(data, j)
+		if end < 0 {
+			// unbalanced entry, the next ones may still be read
+			continue
+		}
+		body := data[j+1 : end]
+		i = end
+		if typ == "string" {
+			for name, value := range parseBibFields(body, macros) {
+				macros[name] = value
+			}
+			continue
+		}
+		if typ == "comment" || typ == "preamble" {
+			continue
+		}
+		comma := strings.IndexByte(body, ',')
+		if comma < 0 {
+			continue
+		}
+		entries = append(entries, BibEntry{
+			Type: typ, Key: strings.TrimSpace(body[:comma]),
+			Fields: parseBibFields(body[comma+1:], macros),
+		})
+	}
+	return entries
+}
+
+// closing returns the index of the delimiter closing the one at start or -1
+func closing(data string, start int) int {
+	open, close := data[start], byte('}')
+	if open == '(' {
+		close = ')'
+	}
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case open:
+			depth++
+		case close:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+		if open == '(' && data[i] == '{' {
+			if end := closing(data, i); end > 0 {
+				i = end
+			}
+		}
+	}
+	return -1
+}
+
+// bibMonths are the macros BibTeX styles define
+var bibMonths = map[string]string{
+	"jan": "January", "feb": "February", "mar": "March", "apr": "April",
+	"may": "May", "jun": "June", "jul": "July", "aug": "August",
+	"sep": "September", "oct": "October", "nov": "November", "dec": "December",
+}
+
+// bibValue reads a field value at the start of rest: braced or quoted strings, numbers
+// and @string macros, concatenated by "#". Returns the value and what follows it
+func bibValue(rest string, macros map[string]string) (string, string, bool) {
+	var value strings.Builder
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if rest == "" {
+			return value.String(), rest, value.Len() > 0
+		}
+		switch rest[0] {
+		case '{':
+			end := closing(rest, 0)
+			if end < 0 {
+				return "", "", false
+			}
+			value.WriteString(rest[1:end])
+			rest = rest[end+1:]
+		case '"':
+			end := closingQuote(rest)
+			if end < 0 {
+				return "", "", false
+			}
+			value.WriteString(rest[1:end])
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ",#} \t\r\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			word := rest[:end]
+			if macro, ok := macros[strings.ToLower(word)]; ok {
+				word = macro
+			} else if month, ok := bibMonths[strings.ToLower(word)]; ok {
+				word = month
+			}
+			value.WriteString(word)
+			rest = rest[end:]
+		}
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if !strings.HasPrefix(rest, "#") {
+			return value.String(), rest, true
+		}
+		rest = rest[1:]
+	}
+}
+
+// closingQuote returns the index of the quote closing the one at the start of s or -1.
+// Quotes escaped or inside braces don't close it
+func closingQuote(s string) int {
+	depth := 0
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBibFields(body string, macros map[string]string) map[string]string {
+	fields := map[string]string{}
+	for len(body) > 0 {
+		eq := strings.IndexByte(body, '=')
+		if eq < 0 {
+			break
+		}
+		name := strings.ToLower(strings.Trim(body[:eq], " \t\r\n,"))
+		value, rest, ok := bibValue(body[eq+1:], macros)
+		if !ok {
+			return fields
+		}
+		fields[name] = value
+		if comma := strings.IndexByte(rest, ','); comma >= 0 {
+			body = rest[comma+1:]
+		} else {
+			body = ""
+		}
+	}
+	return fields
+}
+
+var latexAccentRe = regexp.MustCompile(`\\[^A-Za-z]`)
+var latexCommandRe = regexp.MustCompile(`\\([A-Za-z]+)`)
+
+// normalizeBib strips latex commands, punctuation and case to compare field values
+func normalizeBib(s string) string {
+	s = latexAccentRe.ReplaceAllString(s, "")
+	s = latexCommandRe.ReplaceAllString(s, "$1")
+	return strings.ToLower(keyPart(s))
+}
+
+// sameBib reports whether the entry is the book, by ISBN or by title and first author
+func (b Book) sameBib(e BibEntry) bool {
+	if len(b.ISBNs()) > 0 && len(ParseISBNs(e.Fields["isbn"])) > 0 {
+		return SameISBN(b.ISBN, e.Fields["isbn"])
+	}
+	if normalizeBib(EscapeBibTeX(b.Title)) != normalizeBib(e.Fields["title"]) {
+		return false
+	}
+	authors := b.Authors()
+	if len(authors) == 0 {
+		return e.Fields["author"] == ""
+	}
+	first := strings.Split(e.Fields["author"], " and ")[0]
+	return normalizeBib(EscapeBibTeX(lastName(authors[0]))) == normalizeBib(lastName(first))
+}
+
+// AppendBib appends the book to a BibTeX file shared by many books, keeping what is already there.
+// Returns the key of the entry and false when the book was already in the file.
+// Keys already used by other books get a suffix. Ex: knuth1997art, knuth1997arta
+func AppendBib(fp string, b Book, keyTemplate string) (string, bool, error) {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false, err
+	}
+	keys := map[string]bool{}
+	for _, e := range ParseBibTeX(string(data)) {
+		if b.sameBib(e) {
+			return e.Key, false, nil
+		}
+		keys[strings.ToLower(e.Key)] = true
+	}
+	base := b.CitationKey(keyTemplate)
+	key := base
+	for suffix := 'a'; keys[strings.ToLower(key)]; suffix++ {
+		if suffix > 'z' {
+			return "", false, errors.New("book: no free citation key for " + base)
+		}
+		key = base + string(suffix)
+	}
+	entry := b.ToBIB(keyTemplate)
+	entry = "@book{" + key + entry[strings.IndexByte(entry, ','):]
+	if len(data) > 0 {
+		entry = "\n" + entry
+		if data[len(data)-1] != '\n' {
+			entry = "\n" + entry
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return "", false, err
+	}
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(entry + "\n"); err != nil {
+		return "", false, err
+	}
+	return key, true, nil
+}
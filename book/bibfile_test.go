@@ -0,0 +1,72 @@
+package book
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBibTeX(t *testing.T) {
+	data := `@string{aw = "Addison-Wesley"}
+@STRING(taocp = {The Art of Computer Programming})
+@comment{knuth1997art, title = {Ignored}}
+
+@book{knuth1997art,
+  title     = taocp # ": " # {Fundamental Algorithms},
+  author    = "Knuth, Donald E.",
+  publisher = aw,
+  year      = 1997,
+  month     = jul,
+  note      = "A \"quoted\" {"braced"} note",
+}
+
+@article{ dijkstra1968,
+  title = {Go To Statement Considered {Harmful}},
+  journal = "Communications of the " # {ACM}
+}`
+	want := []BibEntry{
+		{Type: "book", Key: "knuth1997art", Fields: map[string]string{
+			"title":     "The Art of Computer Programming: Fundamental Algorithms",
+			"author":    "Knuth, Donald E.",
+			"publisher": "Addison-Wesley",
+			"year":      "1997",
+			"month":     "July",
+			"note":      `A \"quoted\" {"braced"} note`,
+		}},
+		{Type: "article", Key: "dijkstra1968", Fields: map[string]string{
+			"title":   "Go To Statement Considered {Harmful}",
+			"journal": "Communications of the ACM",
+		}},
+	}
+	if got := ParseBibTeX(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBibTeX = %#v, want %#v", got, want)
+	}
+}
+
+func TestAppendBibWithMacros(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "library.bib")
+	data := `@string{taocp = "The Art of Computer Programming"}
+@book{taocp1,
+  title  = taocp,
+  author = "Knuth, Donald E.",
+}
+`
+	if err := ioutil.WriteFile(fp, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := Book{Title: "The Art of Computer Programming", Author: "Donald E. Knuth", Year: "1997"}
+	key, added, err := AppendBib(fp, b, DefaultCitationKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added || key != "taocp1" {
+		t.Errorf("AppendBib = %q, %v, want the existing entry", key, added)
+	}
+}
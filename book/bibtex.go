@@ -0,0 +1,236 @@
+package book
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCitationKey is used when no citation key template is given.
+// Placeholders: {author} {year} {word} {title} {id}
+const DefaultCitationKey = "{author}{year}{word}"
+
+// latex escapes of characters with special meaning in BibTeX
+var latexEscapes = map[rune]string{
+	'\\': `\textbackslash{}`,
+	'{':  `\{`,
+	'}':  `\}`,
+	'%':  `\%`,
+	'&':  `\&`,
+	'$':  `\$`,
+	'#':  `\#`,
+	'_':  `\_`,
+	'~':  `\textasciitilde{}`,
+	'^':  `\textasciicircum{}`,
+	'ß':  `{\ss}`,
+	'æ':  `{\ae}`,
+	'Æ':  `{\AE}`,
+	'œ':  `{\oe}`,
+	'Œ':  `{\OE}`,
+	'ø':  `{\o}`,
+	'Ø':  `{\O}`,
+	'å':  `{\aa}`,
+	'Å':  `{\AA}`,
+	'ł':  `{\l}`,
+	'Ł':  `{\L}`,
+	'ı':  `{\i}`,
+	'–':  `--`,
+	'—':  `---`,
+	'“':  "``",
+	'”':  "''",
+	'‘':  "`",
+	'’':  "'",
+}
+
+// accented letters and the latex accent command of each group
+var latexAccents = []struct {
+	accent, letters, bases string
+}{
+	{"`", "àèìòùÀÈÌÒÙ", "aeiouAEIOU"},
+	{"'", "áéíóúýćńśźÁÉÍÓÚÝĆŃŚŹ", "aeiouycnszAEIOUYCNSZ"},
+	{"^", "âêîôûÂÊÎÔÛ", "aeiouAEIOU"},
+	{"~", "ãõñÃÕÑ", "aonAON"},
+	{`"`, "äëïöüÿÄËÏÖÜŸ", "aeiouyAEIOUY"},
+	{"c", "çşÇŞ", "csCS"},
+	{"v", "čšžřěňďťČŠŽŘĚŇĎŤ", "cszrendtCSZRENDT"},
+	{"H", "őűŐŰ", "ouOU"},
+	{"k", "ąęĄĘ", "aeAE"},
+	{".", "żŻ", "zZ"},
+}
+
+// asciiFolds are the ASCII letters of non-ASCII ones, used in citation keys
+var asciiFolds = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o",
+	'Ø': "O", 'å': "a", 'Å': "A", 'ł': "l", 'Ł': "L", 'ı': "i",
+}
+
+func init() {
+	for _, a := range latexAccents {
+		bases := []rune(a.bases)
+		for i, r := range []rune(a.letters) {
+			latexEscapes[r] = fmt.Sprintf(`{\%s%c}`, a.accent, bases[i])
+			asciiFolds[r] = string(bases[i])
+		}
+	}
+}
+
+// EscapeBibTeX escapes special characters and accented letters to be used in a BibTeX field
+func EscapeBibTeX(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if e, ok := latexEscapes[r]; ok {
+			sb.WriteString(e)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+var authorSepRe = regexp.MustCompile(`\s*&\s*|\s+and\s+`)
+
+// Authors splits the author field into names. "Last, First" is kept as one author
+func (b Book) Authors() []string {
+	var parts []string
+	if strings.Contains(b.Author, ";") {
+		parts = strings.Split(b.Author, ";")
+	} else {
+		for _, p := range authorSepRe.Split(b.Author, -1) {
+			commas := strings.Split(p, ",")
+			if len(commas) == 2 && len(strings.Fields(commas[0])) == 1 {
+				parts = append(parts, p)
+			} else {
+				parts = append(parts, commas...)
+			}
+		}
+	}
+	authors := []string{}
+	for _, p := range parts {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			authors = append(authors, p)
+		}
+	}
+	return authors
+}
+
+// SplitName returns family and given names of an author as "Last, First" or "First Last"
+func SplitName(author string) (family, given string) {
+	if i := strings.Index(author, ","); i > 0 {
+		return strings.TrimSpace(author[:i]), strings.TrimSpace(author[i+1:])
+	}
+	fields := strings.Fields(author)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return fields[len(fields)-1], strings.Join(fields[:len(fields)-1], " ")
+}
+
+func lastName(author string) string {
+	family, _ := SplitName(author)
+	return family
+}
+
+// words skipped when a title word is used in a citation key
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "on": true, "in": true,
+	"and": true, "to": true, "for": true, "with": true, "from": true,
+}
+
+var keyInvalidRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+var citationKeyRe = regexp.MustCompile(`[^A-Za-z0-9_:\-]+`)
+
+// keyPart transliterates s to ASCII letters and digits
+func keyPart(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if f, ok := asciiFolds[r]; ok {
+			sb.WriteString(f)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return keyInvalidRe.ReplaceAllString(sb.String(), "")
+}
+
+func titleWord(title string) string {
+	for _, word := range strings.Fields(title) {
+		if w := strings.ToLower(keyPart(word)); w != "" && !stopWords[w] {
+			return w
+		}
+	}
+	return ""
+}
+
+// CitationKey makes the BibTeX key of the book from a template. Ex: "{author}{year}{word}"
+func (b Book) CitationKey(template string) string {
+	if template == "" {
+		template = DefaultCitationKey
+	}
+	key := placeholderRe.ReplaceAllStringFunc(template, func(p string) string {
+		switch p {
+		case "{author}":
+			if authors := b.Authors(); len(authors) > 0 {
+				return strings.ToLower(keyPart(lastName(authors[0])))
+			}
+			return ""
+		case "{year}":
+			if year, ok := ParseYear(b.Year); ok {
+				return strconv.Itoa(year)
+			}
+			return ""
+		case "{word}":
+			return titleWord(b.Title)
+		case "{title}":
+			return keyPart(strings.Title(strings.ToLower(b.Title)))
+		case "{id}":
+			return keyPart(b.ID)
+		}
+		return p
+	})
+	key = citationKeyRe.ReplaceAllString(key, "")
+	if key == "" {
+		return "book:" + keyPart(b.ID)
+	}
+	return key
+}
+
+// bibFields returns BibTeX fields of the book in order, with values not escaped
+func (b Book) bibFields() [][2]string {
+	var url string
+	if b.URL != nil {
+		url = b.URL.String()
+	}
+	return [][2]string{
+		{"title", b.Title},
+		{"author", strings.Join(b.Authors(), " and ")},
+		{"publisher", b.Publisher},
+		{"isbn", b.PrimaryISBN()},
+		{"year", b.Year},
+		{"series", b.Series},
+		{"edition", b.Edition},
+		{"volume", b.Volume},
+		{"language", b.Language},
+		{"url", url},
+	}
+}
+
+// ToBIB returns the BibTeX entry of the book, with a key made from keyTemplate. Empty fields are omitted
+func (b Book) ToBIB(keyTemplate string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@book{%s,\n", b.CitationKey(keyTemplate))
+	for _, f := range b.bibFields() {
+		if strings.TrimSpace(f[1]) == "" {
+			continue
+		}
+		value := f[1]
+		// urls are verbatim in BibTeX styles with url support
+		if f[0] != "url" {
+			value = EscapeBibTeX(value)
+		}
+		fmt.Fprintf(&sb, "%-12s =    {%s},\n", f[0], value)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,19 @@
+package book
+
+import "testing"
+
+func TestCitationKey(t *testing.T) {
+	tests := []struct {
+		author, year, title, want string
+	}{
+		{"Donald E. Knuth", "1997", "The Art of Computer Programming", "knuth1997art"},
+		{"Knuth, Donald", "2005, 2006", "Concrete Mathematics", "knuth2005concrete"},
+		{"Knuth, Donald", "", "Concrete Mathematics", "knuthconcrete"},
+	}
+	for _, tt := range tests {
+		b := Book{Author: tt.author, Year: tt.year, Title: tt.title}
+		if got := b.CitationKey(DefaultCitationKey); got != tt.want {
+			t.Errorf("CitationKey(%q, %q) = %q, want %q", tt.author, tt.year, got, tt.want)
+		}
+	}
+}
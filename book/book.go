@@ -1,11 +1,13 @@
 package book
 
 import (
-	"fmt"
+	"errors"
 	"image"
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/josecleiton/godownbook/util"
 )
 
 const (
@@ -19,11 +21,14 @@ const (
 	edition   = "Edition"
 	extension = "Extension"
 	page      = "Pages"
+	language  = "Language"
+	series    = "Series"
 )
 
 type Book struct {
 	Title     string
 	ID        string
+	MD5       string
 	Author    string
 	Publisher string
 	ISBN      string
@@ -36,17 +41,98 @@ type Book struct {
 	URL       *url.URL
 	Language  string
 	Cover     *image.Image
+	CoverURL  *url.URL
 	Synopsis  string
 	Pages     string
 	Mirrors   map[string]*url.URL
 	ExtraInfo map[string]string
+	Subjects  []string
+	// Sources provider of each field filled by enrichment. Fields not in it came from the repository
+	Sources map[string]string
+	// Parsed values of the string fields, filled by Parse. Zero when a field can't be parsed
+	YearNum      int
+	PageCount    int
+	Bytes        int64
+	AuthorList   []Author
+	LanguageCode string
 }
 
 func New() *Book {
 	return &Book{
 		Mirrors:   map[string]*url.URL{},
 		ExtraInfo: map[string]string{},
+		Sources:   map[string]string{},
+	}
+}
+
+// LoadCover fetches the cover from CoverURL if it isn't loaded yet. URLs without scheme are file paths
+func (b *Book) LoadCover() error {
+	if b.Cover != nil {
+		return nil
+	}
+	if b.CoverURL == nil {
+		return errors.New("book: without cover url")
+	}
+	var (
+		img *image.Image
+		err error
+	)
+	if s := b.CoverURL.Scheme; s == "" || s == "file" {
+		img, err = util.ReadImage(b.CoverURL.Path)
+	} else {
+		img, err = util.FetchImage(b.CoverURL)
+	}
+	if err != nil {
+		return err
+	}
+	b.Cover = img
+	return nil
+}
+
+// Merge replaces the fields of b with the non empty ones of other
+func (b *Book) Merge(other *Book) {
+	fields := []struct {
+		dst *string
+		src string
+	}{
+		{&b.Title, other.Title}, {&b.ID, other.ID}, {&b.MD5, other.MD5},
+		{&b.Author, other.Author}, {&b.Publisher, other.Publisher},
+		{&b.ISBN, other.ISBN}, {&b.Year, other.Year}, {&b.Series, other.Series},
+		{&b.Size, other.Size}, {&b.Extension, other.Extension},
+		{&b.Edition, other.Edition}, {&b.Volume, other.Volume},
+		{&b.Language, other.Language}, {&b.Synopsis, other.Synopsis},
+		{&b.Pages, other.Pages},
+	}
+	for _, f := range fields {
+		if strings.TrimSpace(f.src) != "" {
+			*f.dst = f.src
+		}
+	}
+	if other.URL != nil {
+		b.URL = other.URL
+	}
+	if other.Cover != nil {
+		b.Cover = other.Cover
+	}
+	if other.CoverURL != nil {
+		b.CoverURL = other.CoverURL
+	}
+	for k, v := range other.Mirrors {
+		b.Mirrors[k] = v
+	}
+	for k, v := range other.ExtraInfo {
+		b.ExtraInfo[k] = v
 	}
+	if len(other.Subjects) > 0 {
+		b.Subjects = other.Subjects
+	}
+	if b.Sources == nil {
+		b.Sources = map[string]string{}
+	}
+	for k, v := range other.Sources {
+		b.Sources[k] = v
+	}
+	b.Parse()
 }
 
 func (b *Book) Fill(key string, value string) {
@@ -70,29 +156,15 @@ func (b *Book) Fill(key string, value string) {
 		b.Extension = value
 	} else if strings.HasPrefix(key, page) {
 		b.Pages = value
+	} else if strings.HasPrefix(key, language) {
+		b.Language = value
+	} else if strings.HasPrefix(key, series) {
+		b.Series = value
 	} else if value != "" {
 		b.ExtraInfo[key] = value
 	}
 }
 
-func (b Book) ToBIB() string {
-	var url string
-	if b.URL != nil {
-		url = b.URL.String()
-	}
-	return fmt.Sprintf(`@book{book:%s,
-title        =    {%s},
-author       =    {%s},
-publisher    =    {%s},
-isbn         =    {%s},
-year         =    {%s},
-series       =    {%s},
-edition      =    {%s},
-volume       =    {%s},
-url          =    {%s},
-}`, b.ID, b.Title, b.Author, b.Publisher, b.ISBN, b.Year, b.Series, b.Edition, b.Volume, url)
-}
-
 func (b Book) titlePath() (t string) {
 	re := regexp.MustCompile("[[:punct:]]|_|[[:space:]]+")
 	for i, s := range re.Split(b.Title, -1) {
@@ -0,0 +1,156 @@
+package book
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ISBN is a valid ISBN-10 or ISBN-13 without hyphens or spaces
+type ISBN string
+
+var isbnPrefixRe = regexp.MustCompile(`(?i)^(urn:isbn:|isbn(-1[03])?:?)\s*`)
+var isbnSepRe = regexp.MustCompile(`[,;/|]+`)
+
+// ParseISBN normalizes and validates an ISBN. Ex: "ISBN 0-13-110362-8"
+func ParseISBN(s string) (ISBN, error) {
+	s = isbnPrefixRe.ReplaceAllString(strings.TrimSpace(s), "")
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "", "‐", "", "–", "").Replace(s))
+	isbn := ISBN(s)
+	if !isbn.Valid() {
+		return "", errors.New("book: invalid ISBN \"" + s + "\"")
+	}
+	return isbn, nil
+}
+
+// ParseISBNs returns the valid ISBNs of a list, without repetition. Ex: "9780131103627, 0131103628"
+func ParseISBNs(s string) []ISBN {
+	isbns := []ISBN{}
+	seen := map[ISBN]bool{}
+	add := func(isbn ISBN) {
+		if !seen[isbn.ISBN13()] {
+			seen[isbn.ISBN13()] = true
+			isbns = append(isbns, isbn)
+		}
+	}
+	for _, part := range isbnSepRe.Split(s, -1) {
+		if isbn, err := ParseISBN(part); err == nil {
+			add(isbn)
+			continue
+		}
+		// spaces separate ISBNs too when they aren't hyphenation
+		for _, field := range strings.Fields(part) {
+			if isbn, err := ParseISBN(field); err == nil {
+				add(isbn)
+			}
+		}
+	}
+	return isbns
+}
+
+func digit(c byte) int {
+	return int(c - '0')
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDigit10 of the first 9 digits of an ISBN-10
+func checkDigit10(s string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * digit(s[i])
+	}
+	switch check := (11 - sum%11) % 11; check {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + check)
+	}
+}
+
+// checkDigit13 of the first 12 digits of an ISBN-13
+func checkDigit13(s string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * digit(s[i])
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// Valid checks length and check digit
+func (i ISBN) Valid() bool {
+	s := string(i)
+	switch len(s) {
+	case 10:
+		return isDigits(s[:9]) && checkDigit10(s) == s[9]
+	case 13:
+		return isDigits(s) && (strings.HasPrefix(s, "978") || strings.HasPrefix(s, "979")) && checkDigit13(s) == s[12]
+	}
+	return false
+}
+
+// ISBN13 converts an ISBN-10 to ISBN-13. ISBN-13 is returned as it is
+func (i ISBN) ISBN13() ISBN {
+	if len(i) != 10 {
+		return i
+	}
+	s := "978" + string(i[:9])
+	return ISBN(s + string(checkDigit13(s)))
+}
+
+// ISBN10 converts an ISBN-13 to ISBN-10. Only 978 ISBNs have one
+func (i ISBN) ISBN10() (ISBN, bool) {
+	switch {
+	case len(i) == 10:
+		return i, true
+	case len(i) == 13 && strings.HasPrefix(string(i), "978"):
+		s := string(i[3:12])
+		return ISBN(s + string(checkDigit10(s))), true
+	}
+	return "", false
+}
+
+// Equal compares ISBNs of any length
+func (i ISBN) Equal(other ISBN) bool {
+	return i.ISBN13() == other.ISBN13()
+}
+
+func (i ISBN) String() string {
+	return string(i)
+}
+
+// ISBNs returns the valid ISBNs of the book
+func (b Book) ISBNs() []ISBN {
+	return ParseISBNs(b.ISBN)
+}
+
+// PrimaryISBN returns the first valid ISBN as ISBN-13, or the ISBN field as it is when none is valid
+func (b Book) PrimaryISBN() string {
+	if isbns := b.ISBNs(); len(isbns) > 0 {
+		return isbns[0].ISBN13().String()
+	}
+	return strings.TrimSpace(b.ISBN)
+}
+
+// SameISBN reports whether two ISBN lists share a book
+func SameISBN(a, b string) bool {
+	for _, x := range ParseISBNs(a) {
+		for _, y := range ParseISBNs(b) {
+			if x.Equal(y) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package book
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ISBN
+		ok   bool
+	}{
+		{"0131103628", "0131103628", true},
+		{"0-13-110362-8", "0131103628", true},
+		{"978-0 13-110362 7", "9780131103627", true},
+		{"080442957x", "080442957X", true},
+		{"0-8044-2957-X", "080442957X", true},
+		{"ISBN 0-13-110362-8", "0131103628", true},
+		{"ISBN-13: 978-0-13-110362-7", "9780131103627", true},
+		{"ISBN-10: 0131103628", "0131103628", true},
+		{"urn:isbn:9780131103627", "9780131103627", true},
+		{"979-10-323-0569-0", "9791032305690", true},
+		{"0131103627", "", false},
+		{"9780131103628", "", false},
+		{"9770131103628", "", false},
+		{"013110362", "", false},
+		{"X131103628", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseISBN(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseISBN(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseISBNs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []ISBN
+	}{
+		{"9780131103627, 0131103628", []ISBN{"9780131103627"}},
+		{"0131103628; 080442957X", []ISBN{"0131103628", "080442957X"}},
+		{"0131103628 080442957X", []ISBN{"0131103628", "080442957X"}},
+		{"978-0-13-110362-7 / 0-8044-2957-X", []ISBN{"9780131103627", "080442957X"}},
+		{"0131103627, 080442957X", []ISBN{"080442957X"}},
+		{"unknown", []ISBN{}},
+		{"", []ISBN{}},
+	}
+	for _, tt := range tests {
+		if got := ParseISBNs(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseISBNs(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestISBNConversion(t *testing.T) {
+	tests := []struct {
+		in, isbn13, isbn10 ISBN
+		has10              bool
+	}{
+		{"0131103628", "9780131103627", "0131103628", true},
+		{"9780131103627", "9780131103627", "0131103628", true},
+		{"080442957X", "9780804429573", "080442957X", true},
+		{"9780804429573", "9780804429573", "080442957X", true},
+		{"9791032305690", "9791032305690", "", false},
+	}
+	for _, tt := range tests {
+		if got := tt.in.ISBN13(); got != tt.isbn13 {
+			t.Errorf("%s.ISBN13() = %s, want %s", tt.in, got, tt.isbn13)
+		}
+		if got, ok := tt.in.ISBN10(); got != tt.isbn10 || ok != tt.has10 {
+			t.Errorf("%s.ISBN10() = %s, %v, want %s, %v", tt.in, got, ok, tt.isbn10, tt.has10)
+		}
+	}
+}
+
+func TestSameISBN(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0131103628", "978-0-13-110362-7", true},
+		{"ISBN 0-8044-2957-X", "urn:isbn:9780804429573", true},
+		{"9780131103627, 080442957X", "9780804429573", true},
+		{"0131103628 080442957X", "9780804429573", true},
+		{"0131103628", "080442957X", false},
+		{"0131103627", "0131103627", false},
+		{"9791032305690", "1032305690", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := SameISBN(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameISBN(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
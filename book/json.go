@@ -0,0 +1,117 @@
+package book
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// bookJSON is the stable representation of a book. URLs are strings, the cover is its URL or file path
+// and parsed values are left out since Parse fills them back
+type bookJSON struct {
+	Title     string            `json:"title" yaml:"title"`
+	ID        string            `json:"id,omitempty" yaml:"id,omitempty"`
+	MD5       string            `json:"md5,omitempty" yaml:"md5,omitempty"`
+	Author    string            `json:"author,omitempty" yaml:"author,omitempty"`
+	Publisher string            `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+	ISBN      string            `json:"isbn,omitempty" yaml:"isbn,omitempty"`
+	Year      string            `json:"year,omitempty" yaml:"year,omitempty"`
+	Series    string            `json:"series,omitempty" yaml:"series,omitempty"`
+	Size      string            `json:"size,omitempty" yaml:"size,omitempty"`
+	Extension string            `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Edition   string            `json:"edition,omitempty" yaml:"edition,omitempty"`
+	Volume    string            `json:"volume,omitempty" yaml:"volume,omitempty"`
+	URL       string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Language  string            `json:"language,omitempty" yaml:"language,omitempty"`
+	Cover     string            `json:"cover,omitempty" yaml:"cover,omitempty"`
+	Synopsis  string            `json:"synopsis,omitempty" yaml:"synopsis,omitempty"`
+	Pages     string            `json:"pages,omitempty" yaml:"pages,omitempty"`
+	Mirrors   map[string]string `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	ExtraInfo map[string]string `json:"extra,omitempty" yaml:"extra,omitempty"`
+	Subjects  []string          `json:"subjects,omitempty" yaml:"subjects,omitempty"`
+	Sources   map[string]string `json:"sources,omitempty" yaml:"sources,omitempty"`
+}
+
+// URLString returns the string of u, empty when nil
+func URLString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// ParseURL parses s, returning nil when it's empty or invalid
+func ParseURL(s string) *url.URL {
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func (b Book) wire() bookJSON {
+	bj := bookJSON{
+		Title: b.Title, ID: b.ID, MD5: b.MD5, Author: b.Author, Publisher: b.Publisher,
+		ISBN: b.ISBN, Year: b.Year, Series: b.Series, Size: b.Size, Extension: b.Extension,
+		Edition: b.Edition, Volume: b.Volume, URL: URLString(b.URL), Language: b.Language,
+		Cover: URLString(b.CoverURL), Synopsis: b.Synopsis, Pages: b.Pages,
+		ExtraInfo: b.ExtraInfo, Subjects: b.Subjects, Sources: b.Sources,
+	}
+	if len(b.Mirrors) > 0 {
+		bj.Mirrors = make(map[string]string, len(b.Mirrors))
+		for k, u := range b.Mirrors {
+			bj.Mirrors[k] = URLString(u)
+		}
+	}
+	return bj
+}
+
+func (b *Book) fromWire(bj bookJSON) {
+	*b = *New()
+	b.Title, b.ID, b.MD5, b.Author, b.Publisher = bj.Title, bj.ID, bj.MD5, bj.Author, bj.Publisher
+	b.ISBN, b.Year, b.Series, b.Size, b.Extension = bj.ISBN, bj.Year, bj.Series, bj.Size, bj.Extension
+	b.Edition, b.Volume, b.Language, b.Synopsis, b.Pages = bj.Edition, bj.Volume, bj.Language, bj.Synopsis, bj.Pages
+	b.URL = ParseURL(bj.URL)
+	b.CoverURL = ParseURL(bj.Cover)
+	b.Subjects = bj.Subjects
+	for k, s := range bj.Mirrors {
+		if u := ParseURL(s); u != nil {
+			b.Mirrors[k] = u
+		}
+	}
+	for k, v := range bj.ExtraInfo {
+		b.ExtraInfo[k] = v
+	}
+	for k, v := range bj.Sources {
+		b.Sources[k] = v
+	}
+	b.Parse()
+}
+
+func (b Book) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.wire())
+}
+
+func (b *Book) UnmarshalJSON(data []byte) error {
+	var bj bookJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+	b.fromWire(bj)
+	return nil
+}
+
+func (b Book) MarshalYAML() (interface{}, error) {
+	return b.wire(), nil
+}
+
+func (b *Book) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var bj bookJSON
+	if err := unmarshal(&bj); err != nil {
+		return err
+	}
+	b.fromWire(bj)
+	return nil
+}
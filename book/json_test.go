@@ -0,0 +1,90 @@
+package book
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func mustURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func sampleBook(t *testing.T) *Book {
+	b := New()
+	b.Title, b.ID, b.MD5 = "Fundamental Algorithms", "2081744", "6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c"
+	b.Author, b.Publisher, b.ISBN = "Donald E. Knuth", "Addison-Wesley", "0201896834, 9780201896831"
+	b.Year, b.Series, b.Pages, b.Size = "1997", "The Art of Computer Programming", "672", "12 Mb"
+	b.Extension, b.Language, b.Synopsis = "djvu", "English", "Volume one."
+	b.URL = mustURL(t, "http://gen.lib.rus.ec/book/index.php?md5=6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c")
+	b.CoverURL = mustURL(t, "http://gen.lib.rus.ec/covers/2081000/6f5a4b.jpg")
+	b.Mirrors["Libgen.lc"] = mustURL(t, "http://libgen.lc/ads.php?md5=6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c")
+	b.ExtraInfo["DPI"] = "600"
+	b.Subjects = []string{"Algorithms"}
+	b.Sources["Synopsis"] = "openlibrary"
+	b.Parse()
+	return b
+}
+
+func TestBookJSONRoundTrip(t *testing.T) {
+	b := sampleBook(t)
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Book
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*b, got) {
+		t.Errorf("round trip = %+v, want %+v", got, *b)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["cover"] != b.CoverURL.String() || raw["url"] != b.URL.String() {
+		t.Errorf("urls aren't strings: %v %v", raw["cover"], raw["url"])
+	}
+	for _, parsed := range []string{"YearNum", "PageCount", "Bytes", "AuthorList", "Cover"} {
+		if _, ok := raw[parsed]; ok {
+			t.Errorf("%s must not be serialized", parsed)
+		}
+	}
+}
+
+func TestBookYAMLRoundTrip(t *testing.T) {
+	b := sampleBook(t)
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Book
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*b, got) {
+		t.Errorf("round trip = %+v, want %+v", got, *b)
+	}
+}
+
+func TestBookJSONCoverPath(t *testing.T) {
+	var b Book
+	if err := json.Unmarshal([]byte(`{"title": "T", "cover": "/tmp/cover.jpg"}`), &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.CoverURL == nil || b.CoverURL.Scheme != "" || b.CoverURL.Path != "/tmp/cover.jpg" {
+		t.Errorf("cover = %v, want file path", b.CoverURL)
+	}
+	if b.Mirrors == nil || b.Sources == nil {
+		t.Error("maps must be made on unmarshal")
+	}
+}
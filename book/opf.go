@@ -0,0 +1,166 @@
+package book
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CalibreTemplate is the path of books in a Calibre library. Author is the first one
+const CalibreTemplate = "{author}/{title} ({id})/{title} - {author}.{ext}"
+
+// Names of the sidecar files Calibre reads next to a book
+const (
+	OPFName   = "metadata.opf"
+	CoverName = "cover.jpg"
+)
+
+// language codes of the names repositories use
+var languageCodes = map[string]string{
+	"english": "en", "portuguese": "pt", "spanish": "es", "french": "fr",
+	"german": "de", "italian": "it", "russian": "ru", "chinese": "zh",
+	"japanese": "ja", "korean": "ko", "arabic": "ar", "dutch": "nl",
+	"polish": "pl", "turkish": "tr", "ukrainian": "uk", "greek": "el",
+	"latin": "la", "swedish": "sv", "czech": "cs", "hungarian": "hu",
+}
+
+// LanguageCode returns the ISO 639-1 code of a language name or code. Unknown names are ""
+func LanguageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code := languageCodes[language]; code != "" {
+		return code
+	}
+	for _, code := range languageCodes {
+		if code == language {
+			return code
+		}
+	}
+	return ""
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// CalibrePath returns the path of the book in a Calibre library, using "/" as separator
+func (b Book) CalibrePath(maxLen int) string {
+	if authors := b.Authors(); len(authors) > 0 {
+		family, given := SplitName(authors[0])
+		b.Author = strings.TrimSpace(given + " " + family)
+	}
+	return b.PathFromTemplate(CalibreTemplate, maxLen)
+}
+
+// identifier is the unique identifier of the OPF package
+func (b Book) identifier() (scheme, value string) {
+	switch {
+	case b.MD5 != "":
+		return "MD5", b.MD5
+	case b.ID != "":
+		return "godownbook", b.ID
+	}
+	return "ISBN", b.PrimaryISBN()
+}
+
+// ToOPF returns an OPF 2.0 package with the book metadata. cover is the cover file name, if any
+func (b Book) ToOPF(cover string) string {
+	var sb strings.Builder
+	elem := func(name, value string, attrs ...string) {
+		if value = strings.TrimSpace(value); value == "" {
+			return
+		}
+		fmt.Fprintf(&sb, "    <%s", name)
+		for i := 0; i+1 < len(attrs); i += 2 {
+			fmt.Fprintf(&sb, ` %s="%s"`, attrs[i], xmlEscape(attrs[i+1]))
+		}
+		fmt.Fprintf(&sb, ">%s</%s>\n", xmlEscape(value), name)
+	}
+	meta := func(name, content string) {
+		if content = strings.TrimSpace(content); content != "" {
+			fmt.Fprintf(&sb, "    <meta name=\"%s\" content=\"%s\"/>\n", name, xmlEscape(content))
+		}
+	}
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" unique-identifier="bookid" version="2.0">` + "\n")
+	sb.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">` + "\n")
+	scheme, id := b.identifier()
+	if id == "" {
+		id = b.Title
+	}
+	elem("dc:identifier", id, "id", "bookid", "opf:scheme", scheme)
+	if scheme != "ISBN" {
+		elem("dc:identifier", b.PrimaryISBN(), "opf:scheme", "ISBN")
+	}
+	elem("dc:title", b.Title)
+	for _, a := range b.Authors() {
+		family, given := SplitName(a)
+		fileAs := family
+		if given != "" {
+			fileAs += ", " + given
+		}
+		elem("dc:creator", strings.TrimSpace(given+" "+family), "opf:role", "aut", "opf:file-as", fileAs)
+	}
+	elem("dc:publisher", b.Publisher)
+	elem("dc:date", b.Year)
+	if code := LanguageCode(b.Language); code != "" {
+		elem("dc:language", code)
+	} else {
+		elem("dc:language", strings.TrimSpace(b.Language))
+	}
+	elem("dc:description", b.Synopsis)
+	for _, subject := range b.Subjects {
+		elem("dc:subject", subject)
+	}
+	meta("calibre:series", b.Series)
+	if b.Series != "" {
+		meta("calibre:series_index", b.Volume)
+	}
+	sb.WriteString("  </metadata>\n")
+	if cover != "" {
+		fmt.Fprintf(&sb, "  <guide>\n    <reference type=\"cover\" title=\"Cover\" href=\"%s\"/>\n  </guide>\n", xmlEscape(cover))
+	}
+	sb.WriteString("</package>\n")
+	return sb.String()
+}
+
+// CoverJPEG encodes the cover as JPEG
+func (b Book) CoverJPEG() ([]byte, error) {
+	if b.Cover == nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, *b.Cover, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteCalibreSidecar writes metadata.opf and cover.jpg to dir, the folder of the book
+func (b *Book) WriteCalibreSidecar(dir string) error {
+	if b.Cover == nil && b.CoverURL != nil {
+		// the book is still useful without cover
+		b.LoadCover()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	cover := ""
+	data, err := b.CoverJPEG()
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := ioutil.WriteFile(filepath.Join(dir, CoverName), data, 0644); err != nil {
+			return err
+		}
+		cover = CoverName
+	}
+	return ioutil.WriteFile(filepath.Join(dir, OPFName), []byte(b.ToOPF(cover)), 0644)
+}
@@ -0,0 +1,95 @@
+package book
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Placeholders of path templates. Ex: "{author}/{year} - {title}.{ext}"
+var placeholderRe = regexp.MustCompile(`\{[a-z]+\}`)
+
+// invalid characters in any of the common filesystems
+var invalidPathRe = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+var spaceBeforeDotRe = regexp.MustCompile(`\s+\.`)
+
+// reserved names on Windows
+var reservedNameRe = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[1-9]|lpt[1-9])(\..*)?$`)
+
+func (b Book) placeholder(key string) string {
+	switch key {
+	case "{author}":
+		return b.Author
+	case "{title}":
+		return b.Title
+	case "{year}":
+		return b.Year
+	case "{publisher}":
+		return b.Publisher
+	case "{isbn}":
+		return b.ISBN
+	case "{id}":
+		return b.ID
+	case "{series}":
+		return b.Series
+	case "{edition}":
+		return b.Edition
+	case "{language}":
+		return b.Language
+	case "{ext}":
+		return strings.ToLower(b.Extension)
+	}
+	return key
+}
+
+// SanitizeName makes a file name valid in every filesystem and at most maxLen bytes long.
+// The extension is kept when the name is truncated
+func SanitizeName(name string, maxLen int) string {
+	return sanitize(name, maxLen, true)
+}
+
+func sanitize(name string, maxLen int, keepExt bool) string {
+	name = invalidPathRe.ReplaceAllString(name, " ")
+	name = strings.Join(strings.Fields(name), " ")
+	name = spaceBeforeDotRe.ReplaceAllString(name, ".")
+	name = strings.Trim(name, " .-_")
+	if name == "" {
+		name = "unknown"
+	}
+	if reservedNameRe.MatchString(name) {
+		name = "_" + name
+	}
+	if maxLen <= 0 || len(name) <= maxLen {
+		return name
+	}
+	ext := ""
+	if keepExt && len(path.Ext(name)) < maxLen {
+		ext = path.Ext(name)
+	}
+	base := name[:maxLen-len(ext)]
+	for !utf8.ValidString(base) {
+		base = base[:len(base)-1]
+	}
+	return strings.TrimRight(base, " .-_") + ext
+}
+
+// PathFromTemplate returns the relative path of the book file, using "/" as separator.
+// Each component is sanitized and truncated to maxLen bytes
+func (b Book) PathFromTemplate(template string, maxLen int) string {
+	components := strings.Split(template, "/")
+	for i, c := range components {
+		filled := placeholderRe.ReplaceAllStringFunc(c, func(key string) string {
+			// values can't add path components
+			return strings.ReplaceAll(b.placeholder(key), "/", " ")
+		})
+		components[i] = sanitize(filled, maxLen, i == len(components)-1)
+	}
+	return strings.Join(components, "/")
+}
+
+// PathBIB returns the .bib path matching a book path
+func PathBIB(bookPath string) string {
+	return strings.TrimSuffix(bookPath, path.Ext(bookPath)) + ".bib"
+}
@@ -0,0 +1,82 @@
+package book
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/util"
+)
+
+// Author is a parsed author name
+type Author struct {
+	Family string
+	Given  string
+}
+
+// ParseAuthor parses "Last, First" or "First Last"
+func ParseAuthor(name string) Author {
+	family, given := SplitName(name)
+	return Author{Family: family, Given: given}
+}
+
+// String returns the name as "First Last"
+func (a Author) String() string {
+	return strings.TrimSpace(a.Given + " " + a.Family)
+}
+
+// FileAs returns the name as "Last, First"
+func (a Author) FileAs() string {
+	if a.Given == "" {
+		return a.Family
+	}
+	return a.Family + ", " + a.Given
+}
+
+var yearRe = regexp.MustCompile(`\b(1[0-9]{3}|20[0-9]{2})\b`)
+var numberRe = regexp.MustCompile(`\d+`)
+
+// exact bytes some repositories show after the size. Ex: "12 Mb (12582912)"
+var sizeBytesRe = regexp.MustCompile(`\((\d+)\)`)
+
+// ParseBytes returns the bytes of a human readable size
+func ParseBytes(s string) (int64, bool) {
+	if m := sizeBytesRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		return n, err == nil
+	}
+	n, err := util.ParseSize(s)
+	return n, err == nil
+}
+
+// ParseYear returns the first year in s. Ex: "2005, 2006" is 2005
+func ParseYear(s string) (int, bool) {
+	m := yearRe.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(m)
+	return year, true
+}
+
+// ParsePages returns the page count of s. Ex: "320[310]" is 320
+func ParsePages(s string) (int, bool) {
+	m := numberRe.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	pages, err := strconv.Atoi(m)
+	return pages, err == nil && pages > 0
+}
+
+// Parse fills the typed fields from the raw string ones. Fields that can't be parsed are zero
+func (b *Book) Parse() {
+	b.YearNum, _ = ParseYear(b.Year)
+	b.PageCount, _ = ParsePages(b.Pages)
+	b.Bytes, _ = ParseBytes(b.Size)
+	b.AuthorList = nil
+	for _, a := range b.Authors() {
+		b.AuthorList = append(b.AuthorList, ParseAuthor(a))
+	}
+	b.LanguageCode = LanguageCode(b.Language)
+}
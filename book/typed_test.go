@@ -0,0 +1,83 @@
+package book
+
+import "testing"
+
+func TestParseYear(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"1997", 1997, true},
+		{"2005, 2006", 2005, true},
+		{"c. 1968", 1968, true},
+		{"[2011]", 2011, true},
+		{"12345", 0, false},
+		{"0", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseYear(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseYear(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePages(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"672", 672, true},
+		{"320[310]", 320, true},
+		{"xiv, 650", 650, true},
+		{"0", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParsePages(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParsePages(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"12 Mb (12582913)", 12582913, true},
+		{"12 Mb", 12 << 20, true},
+		{"512 kB", 512 << 10, true},
+		{"1,5 GB", 3 << 29, true},
+		{"300 bytes", 300, true},
+		{"unknown", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseBytes(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseLanguageCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"English", "en"},
+		{" portuguese ", "pt"},
+		{"de", "de"},
+		{"Esperanto", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		b := Book{Language: tt.in}
+		b.Parse()
+		if b.LanguageCode != tt.want || b.Language != tt.in {
+			t.Errorf("Parse(Language %q) = %q, %q, want %q", tt.in, b.LanguageCode, b.Language, tt.want)
+		}
+	}
+}
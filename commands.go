@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand run instead of the TUI. Ex: godownbook history
+type command func(args []string) error
+
+var commands = map[string]command{
+	"history":  historyCmd,
+	"batch":    batchCmd,
+	"library":  libraryCmd,
+	"export":   exportCmd,
+	"identify": identifyCmd,
+	"serve":    serveCmd,
+}
+
+func runCommand(name string, args []string) error {
+	cmd := commands[name]
+	if cmd == nil {
+		names := make([]string, 0, len(commands))
+		for k := range commands {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return errors.New(fmt.Sprintf("unknown command \"%s\". Use one of: [%v]", name, strings.Join(names, ", ")))
+	}
+	return cmd(args)
+}
@@ -16,13 +16,88 @@ const (
 	YAML = "yaml"
 )
 
+// Filter default criteria to hide search results. Sizes are human readable (Ex: "50 MB")
+type Filter struct {
+	Language   string
+	Extensions []string
+	YearMin    int
+	YearMax    int
+	SizeMin    string
+	SizeMax    string
+}
+
+// Match rules to pick the best result when downloading without the TUI.
+// Prefer criteria in order of importance: extension, language, year and size
+type Match struct {
+	Extensions []string
+	Language   string
+	Prefer     []string
+}
+
+// Table columns shown in table view. Widths by column name, zero shares the remaining space
+type Table struct {
+	Default bool
+	Columns []string
+	Widths  map[string]int
+}
+
+// SavedSearch is a named search. Pinned ones are listed first in history picker
+type SavedSearch struct {
+	Name       string
+	Term       string
+	Repository string
+	Field      string
+	Sort       string
+	SortMode   string
+	Pinned     bool
+}
+
+// Metadata secondary source of book info looked up by ISBN. Provider is openlibrary or file,
+// URL is the server of openlibrary (empty is openlibrary.org) or the JSON file path of file.
+// Empty provider disables enrichment
+type Metadata struct {
+	Provider string
+	URL      string
+}
+
 type Config struct {
-	OutDir      string
-	OutDirBib   string
+	OutDir    string
+	OutDirBib string
+	// ExportFormats citation formats written after a download: bibtex, biblatex, ris, csljson or endnote
+	ExportFormats []string
+	// MasterBib BibTeX file shared by every book. When set, entries are appended to it
+	// instead of writing a .bib per book in OutDirBib
+	MasterBib string
+	// CitationKey template of BibTeX keys. Ex: "{author}{year}{word}". Placeholders: {author} {year} {word} {title} {id}
+	CitationKey string
+	// NameTemplate path of books inside OutDir. Ex: "{author}/{year} - {title}.{ext}".
+	// Empty uses the hyphenated title
+	NameTemplate string
+	// Calibre saves books as Author/Title (id)/ with metadata.opf and cover.jpg. NameTemplate is ignored
+	Calibre bool
+	// EmbedEPUB rewrites the metadata and cover of downloaded EPUBs with the repository ones
+	EmbedEPUB bool
+	// MaxNameLength max bytes of each path component
+	MaxNameLength int
+	// OnCollision strategy when a file exists: suffix, skip or overwrite
+	OnCollision string
 	DefaultRepo string
+	// Mirror preferred to download books
+	Mirror      string
 	ExecCmd     string
 	Delimiter   string
 	TermUi      bool
+	ShowCover   bool
+	Filter      Filter
+	Match       Match
+	Table       Table
+	Metadata    Metadata
+	HistoryFile string
+	// LibraryFile index of downloaded books
+	LibraryFile string
+	// HistorySize max number of entries listed. Zero disables history
+	HistorySize   int
+	SavedSearches []SavedSearch
 }
 
 var UserConfig *Config
@@ -33,15 +108,48 @@ func Init() (err error) {
 		return
 	}
 	UserConfig = &Config{
-		OutDir:      homeDir,
-		OutDirBib:   homeDir,
-		DefaultRepo: "libgen",
-		Delimiter:   "|",
-		TermUi:      true,
+		OutDir:        homeDir,
+		OutDirBib:     homeDir,
+		CitationKey:   "{author}{year}{word}",
+		ExportFormats: []string{"bibtex"},
+		MaxNameLength: 200,
+		OnCollision:   "suffix",
+		DefaultRepo:   "libgen",
+		Mirror:        "Libgen.lc",
+		Delimiter:     "|",
+		TermUi:        true,
+		ShowCover:     true,
+		Match: Match{
+			Extensions: []string{"epub", "pdf"},
+			Prefer:     []string{"extension", "language", "year", "size"},
+		},
+		HistoryFile: filepath.Join(homeDir, "godownbook", "history.jsonl"),
+		HistorySize: 50,
+		LibraryFile: filepath.Join(homeDir, "godownbook", "library.jsonl"),
 	}
 	return
 }
 
+// ColumnWidth returns the configured width of a table column
+func (t Table) ColumnWidth(column string) int {
+	for k, v := range t.Widths {
+		if strings.EqualFold(k, column) {
+			return v
+		}
+	}
+	return 0
+}
+
+// SavedSearch returns the saved search by name
+func (c *Config) SavedSearch(name string) *SavedSearch {
+	for i := range c.SavedSearches {
+		if strings.EqualFold(c.SavedSearches[i].Name, name) {
+			return &c.SavedSearches[i]
+		}
+	}
+	return nil
+}
+
 func (c *Config) Parse(fp string) error {
 	f, err := os.Open(fp)
 	if err != nil {
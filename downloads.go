@@ -0,0 +1,117 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/repo"
+	w "github.com/josecleiton/godownbook/widget"
+)
+
+const downloadQueueSize = 256
+
+// mark requests rows [from, to] of the current page to be marked. toggle is used for a single row
+type mark struct {
+	from   int
+	to     int
+	toggle bool
+}
+
+// marks are rows selected to download, in the order they were marked
+type marks struct {
+	rows []*repo.BookRow
+	set  map[*repo.BookRow]bool
+}
+
+func newMarks() *marks {
+	return &marks{set: map[*repo.BookRow]bool{}}
+}
+
+func (m *marks) has(row *repo.BookRow) bool {
+	return m != nil && m.set[row]
+}
+
+func (m *marks) toggle(row *repo.BookRow) {
+	if m.set[row] {
+		delete(m.set, row)
+		for i, r := range m.rows {
+			if r == row {
+				m.rows = append(m.rows[:i], m.rows[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+	m.set[row] = true
+	m.rows = append(m.rows, row)
+}
+
+func (m *marks) clear() {
+	m.rows = nil
+	m.set = map[*repo.BookRow]bool{}
+}
+
+// owned counts marked rows already in the local library
+func (m *marks) owned(r repo.Repository) int {
+	n := 0
+	for _, row := range m.rows {
+		if ownedRow(r, row) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// downloadJob is a book of r waiting to be downloaded. Row is resolved to a book when book is nil
+type downloadJob struct {
+	r      repo.Repository
+	row    *repo.BookRow
+	book   *book.Book
+	mirror string
+}
+
+// downloadWorker downloads one book at a time from the queue
+func downloadWorker(jobs chan *downloadJob, mainScreen *w.MainScreen) {
+	for job := range jobs {
+		r, b := job.r, job.book
+		if b == nil {
+			var err error
+			// the row values are enough to download when info page fails
+			if b, err = bookOf(r, job.row); err != nil {
+				log.Println(err)
+			}
+		}
+		downloader, err := r.DownloadBook(job.mirror)
+		if err != nil {
+			log.Println(err)
+			mainScreen.StatusBar.OnError()
+			continue
+		}
+		if _, err := downloadBook(downloader, b, mainScreen.DownloadedFile, mainScreen.UpdateDown); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// downloadNow downloads a book without the TUI, blocking until it's done
+func downloadNow(r repo.Repository, b *book.Book, mirror string) (string, error) {
+	downloader, err := r.DownloadBook(mirror)
+	if err != nil {
+		return "", err
+	}
+	files := make(chan *os.File, 1)
+	progress := make(chan float64)
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-progress:
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer close(done)
+	return downloadBook(downloader, b, files, progress)
+}
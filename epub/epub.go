@@ -0,0 +1,357 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"hash/crc32"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+const containerPath = "META-INF/container.xml"
+
+const mimetype = "application/epub+zip"
+
+// coverID is the manifest id of the cover added to the EPUB
+const coverID = "godownbook-cover"
+
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// packagePath returns the path of the OPF package inside the EPUB
+func packagePath(files map[string]*zip.File) (string, error) {
+	f := files[containerPath]
+	if f == nil {
+		return "", errors.New("epub: " + containerPath + " not found")
+	}
+	data, err := readFile(f)
+	if err != nil {
+		return "", err
+	}
+	var c container
+	if err := xml.Unmarshal(data, &c); err != nil {
+		return "", err
+	}
+	if len(c.Rootfiles) == 0 || files[c.Rootfiles[0].FullPath] == nil {
+		return "", errors.New("epub: package file not found")
+	}
+	return c.Rootfiles[0].FullPath, nil
+}
+
+// PackageFile returns the OPF package of an EPUB
+func PackageFile(fp string) ([]byte, error) {
+	r, err := zip.OpenReader(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	files := map[string]*zip.File{}
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	opf, err := packagePath(files)
+	if err != nil {
+		return nil, err
+	}
+	return readFile(files[opf])
+}
+
+type opfIdentifier struct {
+	Scheme string `xml:"scheme,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type opfMetadata struct {
+	Titles      []string        `xml:"metadata>title"`
+	Creators    []string        `xml:"metadata>creator"`
+	Identifiers []opfIdentifier `xml:"metadata>identifier"`
+	Publisher   string          `xml:"metadata>publisher"`
+	Date        string          `xml:"metadata>date"`
+	Language    string          `xml:"metadata>language"`
+	Description string          `xml:"metadata>description"`
+}
+
+// ReadMetadata returns the book described by the OPF package of an EPUB
+func ReadMetadata(fp string) (*book.Book, error) {
+	data, err := PackageFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	var m opfMetadata
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	b := book.New()
+	if len(m.Titles) > 0 {
+		b.Title = strings.TrimSpace(m.Titles[0])
+	}
+	b.Author = strings.Join(m.Creators, "; ")
+	for _, id := range m.Identifiers {
+		value := strings.TrimSpace(id.Value)
+		// identifiers without ISBN scheme may be ISBNs too
+		if isbn, err := book.ParseISBN(value); err == nil {
+			b.ISBN = isbn.String()
+			if strings.EqualFold(id.Scheme, "isbn") {
+				break
+			}
+		}
+	}
+	b.Publisher = strings.TrimSpace(m.Publisher)
+	if len(m.Date) >= 4 {
+		b.Year = m.Date[:4]
+	}
+	b.Language = m.Language
+	b.Synopsis = m.Description
+	b.Extension = "epub"
+	b.Parse()
+	return b, nil
+}
+
+var metadataRe = regexp.MustCompile(`(?s)(<(?:\w+:)?metadata\b[^>]*>)(.*?)(</(?:\w+:)?metadata>)`)
+var manifestEndRe = regexp.MustCompile(`</(?:\w+:)?manifest>`)
+var dcPrefixRe = regexp.MustCompile(`xmlns:(\w+)="http://purl.org/dc/elements/1.1/"`)
+var versionRe = regexp.MustCompile(`<(?:\w+:)?package\b[^>]*\bversion="(\d)`)
+var coverMetaRe = regexp.MustCompile(`<meta\b[^>]*\bname="cover"[^>]*/>\s*`)
+var coverItemRe = regexp.MustCompile(`<item\b[^>]*\bid="` + coverID + `"[^>]*/>\s*`)
+var idAttrRe = regexp.MustCompile(`\bid="([^"]*)"`)
+
+func elementRe(prefix, name string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?s)<%s:%s\b[^>]*?(?:/>|>.*?</%s:%s>)\s*`, prefix, name, prefix, name))
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// uniqueID returns the id of the identifier the package uses as unique one
+func uniqueID(opf string) string {
+	m := regexp.MustCompile(`\bunique-identifier="([^"]*)"`).FindStringSubmatch(opf)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// rewriteMetadata replaces the book fields of the OPF metadata, keeping everything else
+func rewriteMetadata(opf string, b *book.Book, cover string) (string, error) {
+	m := metadataRe.FindStringSubmatchIndex(opf)
+	if m == nil {
+		return "", errors.New("epub: package without metadata")
+	}
+	open, metadata := opf[m[2]:m[3]], opf[m[4]:m[5]]
+	prefix := "dc"
+	if p := dcPrefixRe.FindStringSubmatch(opf); p != nil {
+		prefix = p[1]
+	} else {
+		open = strings.TrimSuffix(open, ">") + ` xmlns:dc="http://purl.org/dc/elements/1.1/">`
+	}
+	epub3 := false
+	if v := versionRe.FindStringSubmatch(opf); v != nil {
+		epub3 = v[1] == "3"
+	}
+	keep := uniqueID(opf)
+	removed := []string{}
+	remove := func(name string, when func(elem string) bool) {
+		metadata = elementRe(prefix, name).ReplaceAllStringFunc(metadata, func(elem string) string {
+			id := idAttrRe.FindStringSubmatch(elem)
+			if (id != nil && id[1] == keep) || !when(elem) {
+				return elem
+			}
+			if id != nil {
+				removed = append(removed, id[1])
+			}
+			return ""
+		})
+	}
+	always := func(string) bool { return true }
+	fields := [][2]string{{"title", b.Title}, {"publisher", b.Publisher}, {"date", b.Year}, {"description", b.Synopsis}}
+	for _, f := range fields {
+		if strings.TrimSpace(f[1]) != "" {
+			remove(f[0], always)
+		}
+	}
+	authors := b.Authors()
+	if len(authors) > 0 {
+		remove("creator", always)
+	}
+	if b.ISBN != "" {
+		remove("identifier", func(elem string) bool {
+			return strings.Contains(strings.ToLower(elem), "isbn")
+		})
+	}
+	// epub 3 refines the removed elements with meta tags
+	for _, id := range removed {
+		refines := regexp.MustCompile(`(?s)<meta\b[^>]*\brefines="#` + regexp.QuoteMeta(id) + `"[^>]*?(?:/>|>.*?</meta>)\s*`)
+		metadata = refines.ReplaceAllString(metadata, "")
+	}
+	var sb strings.Builder
+	elem := func(name, value string, attrs string) {
+		if value = strings.TrimSpace(value); value != "" {
+			fmt.Fprintf(&sb, "    <%s:%s%s>%s</%s:%s>\n", prefix, name, attrs, xmlEscape(value), prefix, name)
+		}
+	}
+	elem("title", b.Title, "")
+	for _, a := range authors {
+		family, given := book.SplitName(a)
+		attrs := ""
+		if !epub3 {
+			attrs = fmt.Sprintf(` opf:role="aut" opf:file-as="%s"`, xmlEscape(strings.Trim(family+", "+given, ", ")))
+		}
+		elem("creator", strings.TrimSpace(given+" "+family), attrs)
+	}
+	if isbn := b.PrimaryISBN(); isbn != "" && epub3 {
+		elem("identifier", "urn:isbn:"+isbn, "")
+	} else if isbn != "" {
+		elem("identifier", isbn, ` opf:scheme="ISBN"`)
+	}
+	elem("publisher", b.Publisher, "")
+	elem("date", b.Year, "")
+	elem("description", b.Synopsis, "")
+	if cover != "" {
+		metadata = coverMetaRe.ReplaceAllString(metadata, "")
+		fmt.Fprintf(&sb, "    <meta name=\"cover\" content=\"%s\"/>\n", coverID)
+	}
+	metadata = strings.TrimRight(metadata, " \t\r\n") + "\n" + sb.String() + "  "
+	opf = opf[:m[2]] + open + metadata + opf[m[6]:]
+	if cover != "" {
+		opf = coverItemRe.ReplaceAllString(opf, "")
+		props := ""
+		if epub3 {
+			props = ` properties="cover-image"`
+		}
+		item := fmt.Sprintf("    <item id=\"%s\" href=\"%s\" media-type=\"image/jpeg\"%s/>\n  ", coverID, cover, props)
+		loc := manifestEndRe.FindStringIndex(opf)
+		if loc == nil {
+			return "", errors.New("epub: package without manifest")
+		}
+		opf = strings.TrimRight(opf[:loc[0]], " \t") + item + opf[loc[0]:]
+	}
+	return opf, nil
+}
+
+// writeMimetype writes the mimetype file as readers expect it: first, stored,
+// without extra field nor data descriptor
+func writeMimetype(w *zip.Writer) error {
+	header := &zip.FileHeader{
+		Name: "mimetype", Method: zip.Store, CRC32: crc32.ChecksumIEEE([]byte(mimetype)),
+		CompressedSize64: uint64(len(mimetype)), UncompressedSize64: uint64(len(mimetype)),
+	}
+	dst, err := w.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(dst, mimetype)
+	return err
+}
+
+// Embed rewrites the metadata of an EPUB file with the book ones and adds its cover.
+// Every other file of the archive is kept as it is
+func Embed(fp string, b *book.Book) error {
+	r, err := zip.OpenReader(fp)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	files := map[string]*zip.File{}
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	opfPath, err := packagePath(files)
+	if err != nil {
+		return err
+	}
+	opf, err := readFile(files[opfPath])
+	if err != nil {
+		return err
+	}
+	cover, coverPath := "", ""
+	coverData, err := b.CoverJPEG()
+	if err != nil {
+		return err
+	}
+	if coverData != nil {
+		cover = coverID + ".jpg"
+		coverPath = path.Join(path.Dir(opfPath), cover)
+	}
+	newOPF, err := rewriteMetadata(string(opf), b, cover)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fp), ".godownbook-*.epub")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := zip.NewWriter(tmp)
+	if err := writeMimetype(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	for _, f := range r.File {
+		if f.Name == coverPath || f.Name == "mimetype" {
+			continue
+		}
+		header := f.FileHeader
+		dst, err := w.CreateHeader(&header)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if f.Name == opfPath {
+			_, err = io.WriteString(dst, newOPF)
+		} else {
+			var src io.ReadCloser
+			if src, err = f.Open(); err == nil {
+				_, err = io.Copy(dst, src)
+				src.Close()
+			}
+		}
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if coverData != nil {
+		dst, err := w.CreateHeader(&zip.FileHeader{Name: coverPath, Method: zip.Deflate})
+		if err == nil {
+			_, err = dst.Write(coverData)
+		}
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	r.Close()
+	return os.Rename(tmp.Name(), fp)
+}
@@ -0,0 +1,88 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+const testOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Untitled</dc:title>
+    <dc:identifier id="id">urn:uuid:0</dc:identifier>
+  </metadata>
+  <manifest></manifest>
+</package>`
+
+// writeTestEPUB writes an EPUB whose mimetype is compressed and not the first file
+func writeTestEPUB(t *testing.T, fp string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := [][2]string{
+		{containerPath, `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`},
+		{"mimetype", mimetype},
+		{"content.opf", testOPF},
+	}
+	for _, f := range files {
+		dst, err := w.CreateHeader(&zip.FileHeader{Name: f[0], Method: zip.Deflate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst.Write([]byte(f[1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fp, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmbedMimetype(t *testing.T) {
+	dir, err := ioutil.TempDir("", "epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "book.epub")
+	writeTestEPUB(t, fp)
+	b := book.New()
+	b.Title, b.Author = "Fundamental Algorithms", "Donald E. Knuth"
+	if err := Embed(fp, b); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the local header of the first file, as readers check it
+	if len(data) < 30 || binary.LittleEndian.Uint32(data) != 0x04034b50 {
+		t.Fatal("no local file header at the start")
+	}
+	flags, method := binary.LittleEndian.Uint16(data[6:]), binary.LittleEndian.Uint16(data[8:])
+	nameLen, extraLen := binary.LittleEndian.Uint16(data[26:]), binary.LittleEndian.Uint16(data[28:])
+	if flags != 0 || method != zip.Store || extraLen != 0 {
+		t.Errorf("mimetype flags = %#x, method = %d, extra = %d", flags, method, extraLen)
+	}
+	if name := string(data[30 : 30+nameLen]); name != "mimetype" {
+		t.Fatalf("first file = %q", name)
+	}
+	if content := string(data[30+nameLen:][:len(mimetype)]); content != mimetype {
+		t.Errorf("mimetype content = %q", content)
+	}
+	got, err := ReadMetadata(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != b.Title {
+		t.Errorf("title = %q, want %q", got.Title, b.Title)
+	}
+}
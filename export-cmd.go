@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/export"
+)
+
+// exportBooks returns the library books of entry numbers or a search query. Everything when args is empty
+func exportBooks(args []string) ([]*book.Book, error) {
+	entries := localLibrary.Entries()
+	indexes := []int{}
+	for _, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			indexes = localLibrary.Search(strings.Join(args, " "))
+			break
+		}
+		if n < 1 || n > len(entries) {
+			return nil, errors.New("export: no library entry " + a)
+		}
+		indexes = append(indexes, n-1)
+	}
+	if len(args) == 0 {
+		for i := range entries {
+			indexes = append(indexes, i)
+		}
+	}
+	books := make([]*book.Book, len(indexes))
+	for i, idx := range indexes {
+		books[i] = entries[idx].Book()
+	}
+	return books, nil
+}
+
+// exportCmd usage: export [-format bibtex] [-o file] [n... | query]
+func exportCmd(args []string) error {
+	if localLibrary == nil {
+		return errors.New("export: library file not loaded")
+	}
+	format := "bibtex"
+	if len(config.UserConfig.ExportFormats) > 0 {
+		format = strings.Split(config.UserConfig.ExportFormats[0], ",")[0]
+	}
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.StringVar(&format, "format", format, "one of: "+strings.Join(export.Formats(), ", "))
+	out := flags.String("o", "", "output file. Default: stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	e, err := export.Get(format)
+	if err != nil {
+		return err
+	}
+	books, err := exportBooks(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(books) == 0 {
+		return errors.New("export: no books in library to export")
+	}
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return e.Write(w, books, config.UserConfig.CitationKey)
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+type bibTeX struct{}
+
+func (bibTeX) Key() string {
+	return "bibtex"
+}
+
+func (bibTeX) Extension() string {
+	return "bib"
+}
+
+func (bibTeX) Write(w io.Writer, books []*book.Book, keyTemplate string) error {
+	for i, b := range books {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, b.ToBIB(keyTemplate)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bibLaTeX uses the fields of biblatex: date, pagetotal and langid
+type bibLaTeX struct{}
+
+func (bibLaTeX) Key() string {
+	return "biblatex"
+}
+
+func (bibLaTeX) Extension() string {
+	return "bib"
+}
+
+func (bibLaTeX) Write(w io.Writer, books []*book.Book, keyTemplate string) error {
+	for i, raw := range books {
+		// typed fields may not be parsed yet
+		b := *raw
+		b.Parse()
+		var url, pages, year string
+		if b.URL != nil {
+			url = b.URL.String()
+		}
+		// biblatex reads numbers in these fields
+		if b.PageCount > 0 {
+			pages = strconv.Itoa(b.PageCount)
+		}
+		if b.YearNum > 0 {
+			year = strconv.Itoa(b.YearNum)
+		}
+		fields := [][2]string{
+			{"title", book.EscapeBibTeX(b.Title)},
+			{"author", book.EscapeBibTeX(strings.Join(b.Authors(), " and "))},
+			{"publisher", book.EscapeBibTeX(b.Publisher)},
+			{"isbn", b.PrimaryISBN()},
+			{"date", year},
+			{"series", book.EscapeBibTeX(b.Series)},
+			{"edition", book.EscapeBibTeX(b.Edition)},
+			{"volume", b.Volume},
+			{"pagetotal", pages},
+			{"langid", strings.ToLower(b.Language)},
+			{"url", url},
+		}
+		var sb strings.Builder
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "@book{%s,\n", b.CitationKey(keyTemplate))
+		for _, f := range fields {
+			if strings.TrimSpace(f[1]) != "" {
+				fmt.Fprintf(&sb, "  %-10s = {%s},\n", f[0], f[1])
+			}
+		}
+		sb.WriteString("}\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// cslJSON is the citation style language input of Zotero and pandoc
+type cslJSON struct{}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+type cslItem struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	Title           string    `json:"title,omitempty"`
+	Author          []cslName `json:"author,omitempty"`
+	Publisher       string    `json:"publisher,omitempty"`
+	ISBN            string    `json:"ISBN,omitempty"`
+	Issued          *cslDate  `json:"issued,omitempty"`
+	CollectionTitle string    `json:"collection-title,omitempty"`
+	Edition         string    `json:"edition,omitempty"`
+	Volume          string    `json:"volume,omitempty"`
+	NumberOfPages   string    `json:"number-of-pages,omitempty"`
+	Language        string    `json:"language,omitempty"`
+	URL             string    `json:"URL,omitempty"`
+}
+
+func (cslJSON) Key() string {
+	return "csljson"
+}
+
+func (cslJSON) Extension() string {
+	return "json"
+}
+
+func newCSLItem(raw *book.Book, keyTemplate string) cslItem {
+	// typed fields may not be parsed yet
+	b := *raw
+	b.Parse()
+	item := cslItem{
+		ID: b.CitationKey(keyTemplate), Type: "book", Title: b.Title,
+		Publisher: b.Publisher, ISBN: b.PrimaryISBN(), CollectionTitle: b.Series,
+		Edition: b.Edition, Volume: b.Volume, NumberOfPages: b.Pages,
+		Language: b.Language,
+	}
+	for _, a := range b.AuthorList {
+		if a.Given != "" {
+			item.Author = append(item.Author, cslName{Family: a.Family, Given: a.Given})
+		} else {
+			item.Author = append(item.Author, cslName{Literal: a.Family})
+		}
+	}
+	if b.YearNum > 0 {
+		item.Issued = &cslDate{DateParts: [][]int{{b.YearNum}}}
+	}
+	if b.PageCount > 0 {
+		item.NumberOfPages = strconv.Itoa(b.PageCount)
+	}
+	if b.LanguageCode != "" {
+		item.Language = b.LanguageCode
+	}
+	if b.URL != nil {
+		item.URL = b.URL.String()
+	}
+	return item
+}
+
+func (cslJSON) Write(w io.Writer, books []*book.Book, keyTemplate string) error {
+	items := make([]cslItem, len(books))
+	for i, b := range books {
+		items[i] = newCSLItem(b, keyTemplate)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// endNote is the XML format of EndNote libraries
+type endNote struct{}
+
+type endNoteRefType struct {
+	Name  string `xml:"name,attr"`
+	Value int    `xml:",chardata"`
+}
+
+type endNoteRecord struct {
+	RefType   endNoteRefType `xml:"ref-type"`
+	Authors   []string       `xml:"contributors>authors>author"`
+	Title     string         `xml:"titles>title"`
+	Series    string         `xml:"titles>secondary-title,omitempty"`
+	Year      string         `xml:"dates>year,omitempty"`
+	Publisher string         `xml:"publisher,omitempty"`
+	ISBN      string         `xml:"isbn,omitempty"`
+	Edition   string         `xml:"edition,omitempty"`
+	Volume    string         `xml:"volume,omitempty"`
+	Pages     string         `xml:"pages,omitempty"`
+	Language  string         `xml:"language,omitempty"`
+	Label     string         `xml:"label,omitempty"`
+	URL       string         `xml:"urls>related-urls>url,omitempty"`
+}
+
+type endNoteXML struct {
+	XMLName xml.Name        `xml:"xml"`
+	Records []endNoteRecord `xml:"records>record"`
+}
+
+func (endNote) Key() string {
+	return "endnote"
+}
+
+func (endNote) Extension() string {
+	return "xml"
+}
+
+func (endNote) Write(w io.Writer, books []*book.Book, keyTemplate string) error {
+	doc := endNoteXML{Records: make([]endNoteRecord, len(books))}
+	for i, b := range books {
+		r := endNoteRecord{
+			// 6 is the EndNote reference type of books
+			RefType: endNoteRefType{Name: "Book", Value: 6},
+			Authors: b.Authors(), Title: b.Title, Series: b.Series,
+			Year: b.Year, Publisher: b.Publisher, ISBN: b.PrimaryISBN(),
+			Edition: b.Edition, Volume: b.Volume, Pages: b.Pages,
+			Language: b.Language, Label: b.CitationKey(keyTemplate),
+		}
+		if b.URL != nil {
+			r.URL = b.URL.String()
+		}
+		doc.Records[i] = r
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
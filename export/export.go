@@ -0,0 +1,70 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// Exporter writes citations of books in a reference manager format
+type Exporter interface {
+	Key() string
+	// Extension of files written, without the dot
+	Extension() string
+	// Write writes books to w. keyTemplate makes citation keys, see book.CitationKey
+	Write(w io.Writer, books []*book.Book, keyTemplate string) error
+}
+
+var exporters = map[string]Exporter{}
+
+// Register makes an exporter available by its key
+func Register(e Exporter) {
+	exporters[e.Key()] = e
+}
+
+func init() {
+	Register(bibTeX{})
+	Register(bibLaTeX{})
+	Register(ris{})
+	Register(cslJSON{})
+	Register(endNote{})
+}
+
+// Get returns the exporter of a format
+func Get(format string) (Exporter, error) {
+	if e := exporters[strings.ToLower(strings.TrimSpace(format))]; e != nil {
+		return e, nil
+	}
+	return nil, errors.New("export: unknown format \"" + format + "\". Use one of: [" + strings.Join(Formats(), ", ") + "]")
+}
+
+// Formats returns the keys of every exporter
+func Formats() []string {
+	keys := make([]string, 0, len(exporters))
+	for k := range exporters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseFormats returns the exporters of a comma separated list. Ex: "bibtex,ris"
+func ParseFormats(formats []string) ([]Exporter, error) {
+	list := []Exporter{}
+	for _, f := range formats {
+		for _, name := range strings.Split(f, ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			e, err := Get(name)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// ris is the tagged format of Zotero, Mendeley and EndNote imports
+type ris struct{}
+
+func (ris) Key() string {
+	return "ris"
+}
+
+func (ris) Extension() string {
+	return "ris"
+}
+
+func (ris) Write(w io.Writer, books []*book.Book, keyTemplate string) error {
+	for _, b := range books {
+		var sb strings.Builder
+		tag := func(name, value string) {
+			if value = strings.TrimSpace(value); value != "" {
+				fmt.Fprintf(&sb, "%s  - %s\r\n", name, value)
+			}
+		}
+		tag("TY", "BOOK")
+		tag("ID", b.CitationKey(keyTemplate))
+		tag("TI", b.Title)
+		for _, a := range b.Authors() {
+			tag("AU", a)
+		}
+		tag("PB", b.Publisher)
+		for _, isbn := range b.ISBNs() {
+			tag("SN", isbn.ISBN13().String())
+		}
+		tag("PY", b.Year)
+		tag("T3", b.Series)
+		tag("ET", b.Edition)
+		tag("VL", b.Volume)
+		tag("LA", b.Language)
+		if b.URL != nil {
+			tag("UR", b.URL.String())
+		}
+		// end of record has no value
+		sb.WriteString("ER  - \r\n\r\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
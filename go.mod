@@ -1,12 +1,15 @@
 module github.com/josecleiton/godownbook
 
-go 1.14
+go 1.17
 
 require (
 	github.com/gizak/termui/v3 v3.1.0
-	github.com/mattn/go-runewidth v0.0.9 // indirect
-	github.com/mitchellh/go-wordwrap v1.0.0 // indirect
-	github.com/nsf/termbox-go v0.0.0-20200418040025-38ba6e5628f1 // indirect
+	github.com/nsf/termbox-go v0.0.0-20200418040025-38ba6e5628f1
 	golang.org/x/net v0.0.0-20200707034311-ab3426394381
 	gopkg.in/yaml.v2 v2.3.0
 )
+
+require (
+	github.com/mattn/go-runewidth v0.0.9 // indirect
+	github.com/mitchellh/go-wordwrap v1.0.0 // indirect
+)
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/history"
+	"github.com/josecleiton/godownbook/repo"
+)
+
+// savedSearchLabel formats a saved search to be listed
+func savedSearchLabel(s config.SavedSearch) string {
+	label := s.Name + ": " + s.Term
+	if s.Pinned {
+		label = "* " + label
+	}
+	if s.Field != "" {
+		label += " [" + s.Field + "]"
+	}
+	if s.Sort != "" {
+		label += " sort: " + s.Sort + " " + repo.ParseSortMode(s.SortMode).String()
+	}
+	return label
+}
+
+func savedSearchQuery(s config.SavedSearch) *repo.QueryOptions {
+	q := repo.NewQueryOptions(s.Term)
+	q.Field = s.Field
+	q.Sort = s.Sort
+	q.SortMode = repo.ParseSortMode(s.SortMode)
+	return q
+}
+
+// savedSearches returns saved searches with pinned ones first
+func savedSearches() []config.SavedSearch {
+	saved := make([]config.SavedSearch, 0, len(config.UserConfig.SavedSearches))
+	for _, s := range config.UserConfig.SavedSearches {
+		if s.Pinned {
+			saved = append(saved, s)
+		}
+	}
+	for _, s := range config.UserConfig.SavedSearches {
+		if !s.Pinned {
+			saved = append(saved, s)
+		}
+	}
+	return saved
+}
+
+func recentSearches() []history.Entry {
+	if config.UserConfig.HistorySize <= 0 {
+		return []history.Entry{}
+	}
+	entries, err := history.Load(config.UserConfig.HistoryFile)
+	if err != nil {
+		log.Println(err)
+	}
+	return history.Recent(entries, config.UserConfig.HistorySize)
+}
+
+// searchChoices returns saved searches and recent history to be picked
+func searchChoices() ([]string, []*search) {
+	saved := savedSearches()
+	recent := recentSearches()
+	labels := make([]string, 0, len(saved)+len(recent))
+	choices := make([]*search, 0, len(saved)+len(recent))
+	for _, s := range saved {
+		labels = append(labels, savedSearchLabel(s))
+		choices = append(choices, &search{query: savedSearchQuery(s), repository: s.Repository})
+	}
+	for _, e := range recent {
+		labels = append(labels, e.String())
+		choices = append(choices, &search{query: e.QueryOptions(), filter: e.Filter, repository: e.Repository})
+	}
+	return labels, choices
+}
+
+// recordSearch appends search to history file
+func recordSearch(rs *results) {
+	if config.UserConfig.HistorySize <= 0 {
+		return
+	}
+	e := history.NewEntry(rs.r, rs.query, rs.filter, rs.count())
+	if err := history.Append(config.UserConfig.HistoryFile, e); err != nil {
+		log.Println(err)
+	}
+}
+
+func historyList() error {
+	for _, s := range savedSearches() {
+		fmt.Println(savedSearchLabel(s))
+	}
+	for i, e := range recentSearches() {
+		fmt.Printf("%3d. %s\n", i+1, e)
+	}
+	return nil
+}
+
+// historyRun starts TUI with a saved search name or a history entry number
+func historyRun(key string) error {
+	if s := config.UserConfig.SavedSearch(key); s != nil {
+		searchQuery = savedSearchQuery(*s)
+		if s.Repository != "" {
+			repository = s.Repository
+		}
+		runTUI()
+		return nil
+	}
+	n, err := strconv.Atoi(key)
+	recent := recentSearches()
+	if err != nil || n < 1 || n > len(recent) {
+		return errors.New(fmt.Sprintf("history: no saved search or entry \"%s\"", key))
+	}
+	e := recent[n-1]
+	searchQuery = e.QueryOptions()
+	repository = e.Repository
+	if e.Filter != nil {
+		searchFilter = e.Filter
+	}
+	runTUI()
+	return nil
+}
+
+// historyCmd usage: history [list | run <name|n> | clear]
+func historyCmd(args []string) error {
+	if len(args) == 0 {
+		return historyList()
+	}
+	switch args[0] {
+	case "list":
+		return historyList()
+	case "run":
+		if len(args) < 2 {
+			return errors.New("history: run needs a saved search name or entry number")
+		}
+		return historyRun(args[1])
+	case "clear":
+		return history.Clear(config.UserConfig.HistoryFile)
+	}
+	return errors.New("history: usage: history [list | run <name|n> | clear]")
+}
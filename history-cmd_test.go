@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/josecleiton/godownbook/config"
+)
+
+func TestSearchChoicesRepository(t *testing.T) {
+	_, done := newTestConfig(t)
+	defer done()
+	config.UserConfig.HistorySize = 0
+	config.UserConfig.SavedSearches = []config.SavedSearch{
+		{Name: "taocp", Term: "art of computer programming", Repository: "libgen"},
+		{Name: "knuth", Term: "knuth", Pinned: true},
+	}
+	labels, choices := searchChoices()
+	if len(labels) != 2 || len(choices) != 2 {
+		t.Fatalf("choices = %v", labels)
+	}
+	if choices[0].query.Search != "knuth" || choices[0].repository != "" {
+		t.Errorf("pinned choice = %q in %q", choices[0].query.Search, choices[0].repository)
+	}
+	if choices[1].repository != "libgen" {
+		t.Errorf("saved search repository = %q, want libgen", choices[1].repository)
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/josecleiton/godownbook/repo"
+)
+
+// Entry is a search made by the user
+type Entry struct {
+	Term       string       `json:"term"`
+	Repository string       `json:"repository"`
+	Field      string       `json:"field,omitempty"`
+	Sort       string       `json:"sort,omitempty"`
+	SortMode   string       `json:"sortMode,omitempty"`
+	Filter     *repo.Filter `json:"filter,omitempty"`
+	Time       time.Time    `json:"time"`
+	// Results is an estimate when there is more than one page
+	Results int `json:"results"`
+}
+
+// NewEntry makes an entry from the query options used in a search
+func NewEntry(r repo.Repository, q *repo.QueryOptions, f *repo.Filter, results int) Entry {
+	e := Entry{
+		Term: q.Search, Repository: r.Key(), Field: q.Field,
+		Sort: q.Sort, Time: time.Now(), Results: results,
+	}
+	if q.Sort != "" {
+		e.SortMode = q.SortMode.String()
+	}
+	if f != nil && f.Enabled && !f.Empty() {
+		e.Filter = f
+	}
+	return e
+}
+
+// QueryOptions returns the query options to run the search again
+func (e Entry) QueryOptions() *repo.QueryOptions {
+	q := repo.NewQueryOptions(e.Term)
+	q.Field = e.Field
+	q.Sort = e.Sort
+	q.SortMode = repo.ParseSortMode(e.SortMode)
+	return q
+}
+
+// Append writes an entry at the end of history file
+func Append(fp string, e Entry) error {
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(e)
+}
+
+// Load reads history file from the oldest to the newest entry. Missing file is an empty history
+func Load(fp string) ([]Entry, error) {
+	f, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Recent returns up to n entries from the newest to the oldest, skipping repeated searches
+func Recent(entries []Entry, n int) []Entry {
+	recent := make([]Entry, 0, n)
+	seen := map[string]bool{}
+	for i := len(entries) - 1; i >= 0 && len(recent) < n; i-- {
+		e := entries[i]
+		key := e.Repository + "\x00" + e.Field + "\x00" + e.Term
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		recent = append(recent, e)
+	}
+	return recent
+}
+
+// Clear removes history file
+func Clear(fp string) error {
+	err := os.Remove(fp)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (e Entry) String() string {
+	s := e.Term + " (" + e.Repository
+	if e.Field != "" {
+		s += ", in " + e.Field
+	}
+	if e.Sort != "" {
+		s += ", sort: " + e.Sort + " " + e.SortMode
+	}
+	if e.Filter != nil {
+		s += ", filter: " + e.Filter.String()
+	}
+	return s + fmt.Sprintf(", %d results, %s)", e.Results, e.Time.Format("2006-01-02 15:04"))
+}
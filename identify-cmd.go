@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/epub"
+	"github.com/josecleiton/godownbook/metadata"
+	"github.com/josecleiton/godownbook/pdf"
+	"github.com/josecleiton/godownbook/repo"
+)
+
+// readFileMetadata extracts the metadata of a local book file. Unknown formats use the file name as title
+func readFileMetadata(fp string) (*book.Book, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fp), "."))
+	switch ext {
+	case "epub":
+		return epub.ReadMetadata(fp)
+	case "pdf":
+		return pdf.ReadMetadata(fp)
+	}
+	b := book.New()
+	b.Title = strings.TrimSuffix(filepath.Base(fp), filepath.Ext(fp))
+	b.Extension = ext
+	return b, nil
+}
+
+// identifyQuery searches by ISBN when the file has one, otherwise by title and author
+func identifyQuery(r repo.Repository, local *book.Book) *repo.QueryOptions {
+	if isbns := local.ISBNs(); len(isbns) > 0 {
+		return termQuery(r, isbns[0].String())
+	}
+	search := local.Title
+	if authors := local.Authors(); len(authors) > 0 {
+		family, _ := book.SplitName(authors[0])
+		search += " " + family
+	}
+	return repo.NewQueryOptions(search)
+}
+
+// identifyOne returns the repository book matching a local file, or nil when there is none
+func identifyOne(r repo.Repository, rules *repo.MatchRules, local *book.Book) (*book.Book, error) {
+	if strings.TrimSpace(local.Title) == "" && local.ISBN == "" {
+		return nil, errors.New("no title or ISBN in file")
+	}
+	rows, _, err := fetchBookRows(r, identifyQuery(r, local), repo.RowStep)
+	if err == repo.NoRowsError {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// the same format as the file is preferred
+	fileRules := *rules
+	fileRules.Extensions = append([]string{local.Extension}, rules.Extensions...)
+	best, _ := fileRules.Best(r, rows)
+	b, err := bookOf(r, best)
+	if err != nil {
+		log.Println(err)
+	}
+	return b, nil
+}
+
+// identifyCmd usage: identify [-opf] [-local] [-json] <file...>
+func identifyCmd(args []string) error {
+	flags := flag.NewFlagSet("identify", flag.ContinueOnError)
+	withOPF := flags.Bool("opf", false, "write an .opf next to each file too")
+	local := flags.Bool("local", false, "use file metadata when the repository has no match")
+	asJSON := flags.Bool("json", false, "print the identified books as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("identify: usage: identify [-opf] [-local] [-json] <file...>")
+	}
+	r := reposToSearch()
+	rules := matchRules()
+	identified := 0
+	type identifiedFile struct {
+		File string     `json:"file"`
+		Book *book.Book `json:"book"`
+	}
+	results := []identifiedFile{}
+	for _, fp := range flags.Args() {
+		meta, err := readFileMetadata(fp)
+		if err != nil {
+			log.Printf("%s: %v", fp, err)
+			continue
+		}
+		b, err := identifyOne(r, rules, meta)
+		if err != nil {
+			log.Printf("%s: %v", fp, err)
+		} else if b == nil {
+			log.Printf("%s: no match for \"%s\"", fp, meta.Title)
+		}
+		if b == nil && !*local {
+			continue
+		}
+		if b == nil {
+			b = meta
+			if err := metadata.Enrich(metadataProvider, b); err != nil {
+				log.Printf("%s: %v", fp, err)
+			}
+		}
+		written, err := writeCitations(b, book.PathBIB(fp))
+		if err != nil {
+			log.Printf("%s: %v", fp, err)
+			continue
+		}
+		if *withOPF {
+			opfPath := strings.TrimSuffix(fp, filepath.Ext(fp)) + ".opf"
+			if err := ioutil.WriteFile(opfPath, []byte(b.ToOPF("")), 0644); err != nil {
+				log.Printf("%s: %v", fp, err)
+				continue
+			}
+		}
+		identified++
+		if *asJSON {
+			results = append(results, identifiedFile{File: fp, Book: b})
+			continue
+		}
+		fmt.Printf("%s: %s - %s [%s]\n", fp, b.Title, b.Author, written)
+	}
+	if *asJSON {
+		return printJSON(results)
+	}
+	fmt.Printf("%d of %d files identified\n", identified, flags.NArg())
+	return nil
+}
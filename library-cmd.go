@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/library"
+)
+
+// libraryEntry parses a library entry number as listed by library list
+func libraryEntry(key string) (int, library.Entry, error) {
+	entries := localLibrary.Entries()
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 1 || n > len(entries) {
+		return 0, library.Entry{}, errors.New(fmt.Sprintf("library: no entry \"%s\"", key))
+	}
+	return n - 1, entries[n-1], nil
+}
+
+func libraryList(entries []library.Entry, indexes []int) {
+	for _, i := range indexes {
+		fmt.Printf("%3d. %s\n", i+1, entries[i])
+	}
+}
+
+// libraryRemove removes an entry, deleting its files if asked to
+func libraryRemove(args []string) error {
+	flags := flag.NewFlagSet("library remove", flag.ContinueOnError)
+	del := flags.Bool("delete", false, "delete the book file too")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("library: usage: library remove [-delete] <n>")
+	}
+	i, e, err := libraryEntry(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *del {
+		if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return localLibrary.Remove(i)
+}
+
+// libraryCmd usage: library [list | search <query> | show <n> | remove [-delete] <n>]
+func libraryCmd(args []string) error {
+	if localLibrary == nil {
+		return errors.New("library: library file not loaded")
+	}
+	entries := localLibrary.Entries()
+	if len(args) == 0 || args[0] == "list" {
+		indexes := make([]int, len(entries))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		libraryList(entries, indexes)
+		return nil
+	}
+	switch args[0] {
+	case "search":
+		if len(args) < 2 {
+			return errors.New("library: search needs a query")
+		}
+		libraryList(entries, localLibrary.Search(strings.Join(args[1:], " ")))
+		return nil
+	case "show":
+		if len(args) < 2 {
+			return errors.New("library: show needs an entry number")
+		}
+		_, e, err := libraryEntry(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Print(e.Details())
+		return nil
+	case "remove":
+		return libraryRemove(args[1:])
+	}
+	return errors.New("library: usage: library [list | search <query> | show <n> | remove [-delete] <n>]")
+}
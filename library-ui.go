@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/library"
+	"github.com/josecleiton/godownbook/util"
+)
+
+// openCommand returns the command of the system file viewer
+func openCommand(fp string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", fp)
+	case "windows":
+		return exec.Command("cmd", "/c", "start", "", fp)
+	}
+	return exec.Command("xdg-open", fp)
+}
+
+// openEntry opens the book file with the system viewer
+func openEntry(e library.Entry) (string, error) {
+	if _, err := os.Stat(e.Path); err != nil {
+		return "", err
+	}
+	if err := openCommand(e.Path).Start(); err != nil {
+		return "", err
+	}
+	return "opening " + filepath.Base(e.Path), nil
+}
+
+// entryBibPath returns where the .bib of a book in the library is written
+func entryBibPath(e library.Entry) string {
+	cfg := config.UserConfig
+	rel, err := filepath.Rel(cfg.OutDir, e.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return book.PathBIB(e.Path)
+	}
+	return filepath.Join(cfg.OutDirBib, book.PathBIB(rel))
+}
+
+// exportEntryBib writes the citations of a book in the library again, like after its download
+func exportEntryBib(e library.Entry) (string, error) {
+	bibPath := ""
+	if !onlyMasterBib() {
+		bibPath = entryBibPath(e)
+		if err := os.MkdirAll(filepath.Dir(bibPath), 0755); err != nil {
+			return "", err
+		}
+	}
+	fp, err := writeCitations(e.Book(), bibPath)
+	if err != nil {
+		return "", err
+	}
+	if fp == "" {
+		return "no citation format configured", nil
+	}
+	return filepath.Base(fp) + " exported", nil
+}
+
+// verifyEntry compares the book file checksum with the one recorded at download
+func verifyEntry(e library.Entry) (string, error) {
+	sum, err := util.FileMD5(e.Path)
+	if err != nil {
+		return "", err
+	}
+	if e.FileMD5() == "" {
+		return "no checksum recorded, md5 is " + sum, nil
+	}
+	if !strings.EqualFold(sum, e.FileMD5()) {
+		return "", errors.New("checksum mismatch: " + sum)
+	}
+	return "checksum ok", nil
+}
+
+// deleteEntry removes the book file, its .bib and the library entry
+func deleteEntry(e library.Entry) (string, error) {
+	for _, p := range []string{e.Path, entryBibPath(e)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if err := localLibrary.RemoveEntry(e); err != nil {
+		return "", err
+	}
+	return filepath.Base(e.Path) + " deleted", nil
+}
+
+// runLibraryAction runs action on an entry and sends the outcome to be shown
+func runLibraryAction(bc *BookController, action func(library.Entry) (string, error), e library.Entry) {
+	msg, err := action(e)
+	if err != nil {
+		msg = "[" + err.Error() + "](fg:red)"
+	}
+	bc.LibraryDone <- msg
+}
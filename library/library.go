@@ -0,0 +1,304 @@
+package library
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// Entry is a book file downloaded before
+type Entry struct {
+	// MD5 is the checksum of the file as the repository serves it
+	MD5 string `json:"md5"`
+	// Checksum is the md5 of the file written when it differs from MD5, like after embedding EPUB metadata
+	Checksum   string    `json:"checksum,omitempty"`
+	ID         string    `json:"id,omitempty"`
+	ISBN       string    `json:"isbn,omitempty"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	Publisher  string    `json:"publisher,omitempty"`
+	Year       string    `json:"year,omitempty"`
+	Series     string    `json:"series,omitempty"`
+	Edition    string    `json:"edition,omitempty"`
+	Volume     string    `json:"volume,omitempty"`
+	Language   string    `json:"language,omitempty"`
+	Extension  string    `json:"extension,omitempty"`
+	Size       string    `json:"size,omitempty"`
+	Pages      string    `json:"pages,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	Mirror     string    `json:"mirror,omitempty"`
+	Time       time.Time `json:"time"`
+	Path       string    `json:"path"`
+}
+
+// Library indexes the downloaded books in a JSON lines file
+type Library struct {
+	fp      string
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+var nonAlnumRe = regexp.MustCompile(`[^\pL\pN]+`)
+
+// Normalize lowercases and drops punctuation to compare titles and authors
+func Normalize(s string) string {
+	return strings.TrimSpace(nonAlnumRe.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+// Open loads library file. Missing file is an empty library
+func Open(fp string) (*Library, error) {
+	l := &Library{fp: fp, entries: []Entry{}}
+	f, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		l.entries = append(l.entries, e)
+	}
+	return l, scanner.Err()
+}
+
+// NewEntry makes an entry of a book downloaded to fp
+func NewEntry(b *book.Book, fp, md5, repository, mirror string) Entry {
+	e := Entry{
+		MD5: md5, ID: b.ID, ISBN: b.ISBN, Title: b.Title, Author: b.Author,
+		Publisher: b.Publisher, Year: b.Year, Series: b.Series, Edition: b.Edition,
+		Volume: b.Volume, Language: b.Language, Extension: b.Extension,
+		Size: b.Size, Pages: b.Pages, Repository: repository, Mirror: mirror,
+		Time: time.Now(), Path: fp,
+	}
+	if b.URL != nil {
+		e.URL = b.URL.String()
+	}
+	return e
+}
+
+// FileMD5 returns the md5 the file on disk must have
+func (e Entry) FileMD5() string {
+	if e.Checksum != "" {
+		return e.Checksum
+	}
+	return e.MD5
+}
+
+// Book returns the metadata of the entry as a book
+func (e Entry) Book() *book.Book {
+	b := book.New()
+	b.MD5, b.ID, b.ISBN, b.Title, b.Author = e.MD5, e.ID, e.ISBN, e.Title, e.Author
+	b.Publisher, b.Year, b.Series, b.Edition, b.Volume = e.Publisher, e.Year, e.Series, e.Edition, e.Volume
+	b.Language, b.Extension, b.Size, b.Pages = e.Language, e.Extension, e.Size, e.Pages
+	if e.URL != "" {
+		b.URL, _ = url.Parse(e.URL)
+	}
+	b.Parse()
+	return b
+}
+
+func (e Entry) String() string {
+	s := e.Title
+	if e.Author != "" {
+		s += " - " + e.Author
+	}
+	if e.Year != "" {
+		s += " (" + e.Year + ")"
+	}
+	return s + " [" + e.Path + "]"
+}
+
+// Details formats every field of the entry, one per line
+func (e Entry) Details() string {
+	fields := [][2]string{
+		{"Title", e.Title}, {"Author", e.Author}, {"Publisher", e.Publisher},
+		{"Year", e.Year}, {"Series", e.Series}, {"Edition", e.Edition},
+		{"Volume", e.Volume}, {"ISBN", e.ISBN}, {"ID", e.ID},
+		{"Language", e.Language}, {"Extension", e.Extension}, {"Size", e.Size},
+		{"Pages", e.Pages}, {"URL", e.URL}, {"Repository", e.Repository},
+		{"Mirror", e.Mirror}, {"Downloaded", e.Time.Format(time.RFC1123)},
+		{"MD5", e.MD5}, {"Checksum", e.Checksum}, {"Path", e.Path},
+	}
+	var sb strings.Builder
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", f[0], f[1])
+		}
+	}
+	return sb.String()
+}
+
+// Add appends an entry to library file
+func (l *Library) Add(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.fp), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		return err
+	}
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+// Entries returns every entry from the oldest to the newest
+func (l *Library) Entries() []Entry {
+	if l == nil {
+		return []Entry{}
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Entry{}, l.entries...)
+}
+
+// Search returns the indexes of entries whose title, author, publisher, series or ISBN has every word of query
+func (l *Library) Search(query string) []int {
+	words := strings.Fields(Normalize(query))
+	found := []int{}
+	if _, err := book.ParseISBN(query); err == nil {
+		for i, e := range l.Entries() {
+			if book.SameISBN(query, e.ISBN) {
+				found = append(found, i)
+			}
+		}
+		return found
+	}
+	for i, e := range l.Entries() {
+		text := Normalize(strings.Join([]string{e.Title, e.Author, e.Publisher, e.Series, e.ISBN}, " "))
+		match := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// Remove deletes the entry at index i and rewrites library file
+func (l *Library) Remove(i int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.remove(i)
+}
+
+// remove deletes the entry at index i. Must be called with l.mu held
+func (l *Library) remove(i int) error {
+	if i < 0 || i >= len(l.entries) {
+		return errors.New("library: no entry " + fmt.Sprint(i+1))
+	}
+	entries := append(append([]Entry{}, l.entries[:i]...), l.entries[i+1:]...)
+	if err := l.write(entries); err != nil {
+		return err
+	}
+	l.entries = entries
+	return nil
+}
+
+// RemoveEntry deletes the entry with the path and md5 of e and rewrites library file.
+// Unlike Remove it doesn't depend on indexes that may change meanwhile
+func (l *Library) RemoveEntry(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, other := range l.entries {
+		if other.Path == e.Path && strings.EqualFold(other.MD5, e.MD5) {
+			return l.remove(i)
+		}
+	}
+	return errors.New("library: no entry of " + e.Path)
+}
+
+// write replaces library file with entries
+func (l *Library) write(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(l.fp), 0755); err != nil {
+		return err
+	}
+	tmp := l.fp + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.fp)
+}
+
+// find returns the newest entry that matches and whose file still exists
+func (l *Library) find(match func(e *Entry) bool) *Entry {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := &l.entries[i]
+		if !match(e) {
+			continue
+		}
+		if _, err := os.Stat(e.Path); err == nil {
+			found := *e
+			return &found
+		}
+	}
+	return nil
+}
+
+func sameBook(e *Entry, title, author string) bool {
+	return title != "" && Normalize(e.Title) == title && Normalize(e.Author) == author
+}
+
+// FindRow returns the entry of a search row by md5 or by title and author
+func (l *Library) FindRow(md5, title, author string) *Entry {
+	md5 = strings.ToLower(md5)
+	title, author = Normalize(title), Normalize(author)
+	return l.find(func(e *Entry) bool {
+		return (md5 != "" && e.MD5 == md5) || sameBook(e, title, author)
+	})
+}
+
+// FindBook returns the entry of a book by md5, ISBN or title and author
+func (l *Library) FindBook(b *book.Book) *Entry {
+	md5 := strings.ToLower(b.MD5)
+	title, author := Normalize(b.Title), Normalize(b.Author)
+	return l.find(func(e *Entry) bool {
+		return (md5 != "" && e.MD5 == md5) ||
+			book.SameISBN(b.ISBN, e.ISBN) ||
+			sameBook(e, title, author)
+	})
+}
@@ -0,0 +1,82 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+func TestFindRowAfterEmbedding(t *testing.T) {
+	dir, err := ioutil.TempDir("", "library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "book.epub")
+	if err := ioutil.WriteFile(fp, []byte("rewritten"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(filepath.Join(dir, "library.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := book.New()
+	b.Title, b.Author = "Fundamental Algorithms", "Donald E. Knuth"
+	e := NewEntry(b, fp, "6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c", "libgen", "Libgen.lc")
+	e.Checksum = "0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+	if err := l.Add(e); err != nil {
+		t.Fatal(err)
+	}
+	// reopen to check the checksum is stored too
+	if l, err = Open(filepath.Join(dir, "library.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	found := l.FindRow("6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c", "Another Title", "Someone")
+	if found == nil {
+		t.Fatal("entry not found by repository md5")
+	}
+	if found.FileMD5() != e.Checksum {
+		t.Errorf("file md5 = %q, want %q", found.FileMD5(), e.Checksum)
+	}
+	if l.FindRow(e.Checksum, "Another Title", "Someone") != nil {
+		t.Error("entry found by the checksum of the rewritten file")
+	}
+}
+
+func TestRemoveEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l, err := Open(filepath.Join(dir, "library.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []Entry
+	for _, title := range []string{"Fundamental Algorithms", "Seminumerical Algorithms", "Sorting and Searching"} {
+		b := book.New()
+		b.Title = title
+		e := NewEntry(b, filepath.Join(dir, title+".pdf"), "", "libgen", "Libgen.lc")
+		if err := l.Add(e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	// the index of the last entry changes once the first is removed
+	if err := l.RemoveEntry(entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RemoveEntry(entries[2]); err != nil {
+		t.Fatal(err)
+	}
+	if left := l.Entries(); len(left) != 1 || left[0].Path != entries[1].Path {
+		t.Errorf("entries = %v", left)
+	}
+	if err := l.RemoveEntry(entries[2]); err == nil {
+		t.Error("removed a missing entry")
+	}
+}
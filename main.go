@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"image"
 	"log"
 	"os"
 	"os/signal"
@@ -11,7 +12,10 @@ import (
 	"sync"
 
 	ui "github.com/gizak/termui/v3"
+	"github.com/josecleiton/godownbook/book"
 	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/library"
+	"github.com/josecleiton/godownbook/metadata"
 	"github.com/josecleiton/godownbook/repo"
 	"github.com/josecleiton/godownbook/repo/libgen"
 	"github.com/josecleiton/godownbook/util"
@@ -20,9 +24,16 @@ import (
 )
 
 var searchPattern string
+var searchField string
+var searchQuery *repo.QueryOptions
+var searchFilter *repo.Filter
 var verboseFlag bool
 var repository string
 var configPath string
+var exportFormats string
+var jsonOutput bool
+var localLibrary *library.Library
+var metadataProvider metadata.Provider
 
 var wRender = &sync.Mutex{}
 
@@ -34,7 +45,8 @@ var supportedRepositories = map[string]repo.Repository{
 	"libgen": libgen.Make(),
 }
 
-func init() {
+// setup loads the config and parses the flags. Subcommands and TUI run after it
+func setup() {
 	err := config.Init()
 	if err != nil {
 		log.Fatalln(err)
@@ -46,13 +58,33 @@ func init() {
 	cfgdir := filepath.Join(ucdir, "godownbook")
 	flag.StringVar(&configPath, "c", filepath.Join(cfgdir, "config.json"), "config file path")
 	flag.StringVar(&searchPattern, "s", "", "book title to search")
+	flag.StringVar(&searchField, "f", "", "column to search in. Ex: author")
 	flag.BoolVar(&verboseFlag, "v", false, "verbose log")
 	flag.StringVar(&repository, "r", "", "where to lookup book")
+	flag.StringVar(&exportFormats, "x", "", "citation formats written after a download, comma separated. Ex: bibtex,ris")
+	flag.BoolVar(&jsonOutput, "json", false, "print search results as JSON instead of opening the TUI")
 	flag.Parse()
 	parseConfigFile(cfgdir)
 	if repository == "" {
 		repository = config.UserConfig.DefaultRepo
 	}
+	if exportFormats != "" {
+		config.UserConfig.ExportFormats = []string{exportFormats}
+	}
+	searchQuery = repo.NewQueryOptions(searchPattern)
+	if searchField != "" {
+		searchQuery.Field = searchField
+	} else if isbn, err := book.ParseISBN(searchPattern); err == nil {
+		searchQuery = termQuery(reposToSearch(), isbn.String())
+	}
+	searchFilter = newFilter(config.UserConfig.Filter)
+	if localLibrary, err = library.Open(config.UserConfig.LibraryFile); err != nil {
+		log.Println(err)
+	}
+	md := config.UserConfig.Metadata
+	if metadataProvider, err = metadata.New(md.Provider, md.URL); err != nil {
+		log.Println(err)
+	}
 }
 
 func parseConfigFile(cdir string) {
@@ -112,19 +144,33 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 		LIST PageType = iota
 		MODAL
 		PAGES
+		SEARCH
+		HISTORY
+		LIBRARY
 	)
 	defer func() { done <- true }()
 	var modal *w.BookModal
-	l := mainScreen.BookList
+	l := mainScreen.View()
 	highlighted := LIST
-	sigTerm := make(chan os.Signal)
+	sigTerm := make(chan os.Signal, 1)
 	signal.Notify(sigTerm, os.Interrupt)
 	signal.Notify(sigTerm, os.Kill)
 	previousKey := ""
+	// first row of a range mark
+	markAnchor := 0
 	wRender.Lock()
 	uiEvents := ui.PollEvents()
 	ui.Render(mainScreen)
+	tw, th := ui.TerminalDimensions()
 	wRender.Unlock()
+	prompt := w.NewSearchPrompt(tw, th)
+	recent := recentSearches()
+	for i := len(recent) - 1; i >= 0; i-- {
+		prompt.AddHistory(recent[i].Term)
+	}
+	var picker *w.SearchPicker
+	var choices []*search
+	libraryScreen := w.NewLibraryScreen(localLibrary.Entries(), tw, th)
 	for {
 		select {
 		case <-sigTerm:
@@ -134,27 +180,79 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 				highlighted = MODAL
 				lockAndRender(modal)
 			}
+		case b := <-bc.CoverLoaded:
+			if modal == nil || modal.Data != b {
+				break
+			}
+			var cover image.Image
+			if b.Cover != nil {
+				cover = *b.Cover
+			}
+			modal.SetCover(cover)
+			if highlighted == MODAL {
+				lockAndRender(modal)
+			}
+		case msg := <-bc.LibraryDone:
+			libraryScreen.SetEntries(localLibrary.Entries())
+			libraryScreen.OnMessage(msg)
+			if highlighted == LIBRARY {
+				lockAndRender(libraryScreen)
+			}
 		case percentage := <-mainScreen.UpdateDown:
 			mainScreen.StatusBar.OnProgress(percentage)
 			lockAndRender(mainScreen)
 		case f := <-mainScreen.DownloadedFile:
+			if f == nil {
+				mainScreen.StatusBar.OnError()
+				mainScreen.StatusBar.OnMessage("download failed")
+				lockAndRender(mainScreen)
+				break
+			}
 			f.Close()
 			mainScreen.StatusBar.OnMessage(filepath.Base(f.Name()) + " downloaded")
 			mainScreen.StatusBar.OnFinished()
 			lockAndRender(mainScreen)
 			mainScreen.StatusBar.OnMessage("")
-		case <-mainScreen.SelectedRow:
+		case bl := <-mainScreen.UpdateList:
+			mainScreen.SetBookList(bl)
+			lockAndRender(mainScreen)
+		case bt := <-mainScreen.UpdateTable:
+			mainScreen.SetBookTable(bt)
+			lockAndRender(mainScreen)
+		case tree := <-mainScreen.UpdateTree:
+			mainScreen.SetBookTree(tree)
+			lockAndRender(mainScreen)
+		case pi := <-mainScreen.UpdatePages:
+			mainScreen.SetPageIndicator(pi)
+			lockAndRender(mainScreen)
 		case e := <-uiEvents:
-			l = mainScreen.BookList
+			l = mainScreen.View()
 			// global key maps
 			switch e.ID {
-			case "q", "<C-c>":
+			case "<C-c>":
 				return
+			case "q":
+				if highlighted != SEARCH && highlighted != HISTORY {
+					return
+				}
 			}
 			if highlighted == LIST {
 				switch e.ID {
-				case "d", "D":
+				case "d":
 					return
+				case "D":
+					go func() { bc.DownloadMarked <- true }()
+				case "<Space>":
+					if mainScreen.Mode != w.TreeView {
+						m := &mark{from: l.Selected(), toggle: true}
+						markAnchor = m.from
+						go func() { bc.Mark <- m }()
+					}
+				case "V":
+					if mainScreen.Mode != w.TreeView {
+						m := &mark{from: markAnchor, to: l.Selected()}
+						go func() { bc.Mark <- m }()
+					}
 				case "j", "<Down>":
 					l.ScrollDown()
 				case "k", "<Up>":
@@ -176,13 +274,56 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 				case "<Home>":
 					l.ScrollTop()
 				case "<Enter>":
-					mainScreen.SelectedRow <- l.SelectedRow
+					if mainScreen.Mode == w.TreeView {
+						if idx, ok := mainScreen.BookTree.SelectedIndex(); ok {
+							go func() { mainScreen.SelectedNode <- idx }()
+						} else {
+							mainScreen.BookTree.ToggleExpand()
+						}
+						break
+					}
+					selectedRow := l.Selected()
+					go func() { mainScreen.SelectedRow <- selectedRow }()
+				case "<Right>":
+					mainScreen.BookTree.Expand()
+				case "<Left>":
+					mainScreen.BookTree.Collapse()
+				case "E":
+					mainScreen.BookTree.ExpandAll()
+				case "C":
+					mainScreen.BookTree.CollapseAll()
+				case "a", "A":
+					go func() { bc.CycleGroup <- true }()
+				case "f", "F":
+					go func() { bc.ToggleFilter <- true }()
+				case "/", "s":
+					prompt.Open()
+					highlighted = SEARCH
+				case "h":
+					var labels []string
+					labels, choices = searchChoices()
+					tw, th := ui.TerminalDimensions()
+					picker = w.NewSearchPicker(labels, tw, th)
+					highlighted = HISTORY
+				case "o":
+					go func() { bc.CycleSort <- true }()
+				case "O":
+					go func() { bc.ToggleSortMode <- true }()
 				case "G", "<End>":
 					l.ScrollBottom()
 				case "<Resize>":
 					handleResize(mainScreen)
-				case "<Tab>", "p", "P":
-					toggleHighlight(mainScreen.PageIndicator, mainScreen.BookList)
+				case "t", "T":
+					mainScreen.NextView()
+				case "<Tab>", "l":
+					handleResize(libraryScreen)
+					libraryScreen.SetEntries(localLibrary.Entries())
+					libraryScreen.OnMessage("")
+					highlighted = LIBRARY
+					lockAndRender(libraryScreen)
+					continue
+				case "p", "P":
+					toggleHighlight(mainScreen.PageIndicator, mainScreen)
 					highlighted = PAGES
 				}
 				if num, err := strconv.Atoi(e.ID); (num > 0 || previousKey != "") && err == nil {
@@ -196,7 +337,7 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 							num = 25
 						}
 					}
-					l.SelectedRow = num - 1
+					l.Select(num - 1)
 				} else {
 					if previousKey == "g" {
 						previousKey = ""
@@ -204,11 +345,98 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 						previousKey = e.ID
 					}
 				}
-				lockAndRender(mainScreen)
+				switch highlighted {
+				case SEARCH:
+					lockAndRender(mainScreen, prompt)
+				case HISTORY:
+					lockAndRender(mainScreen, picker)
+				default:
+					lockAndRender(mainScreen)
+				}
+			} else if highlighted == SEARCH {
+				switch e.ID {
+				case "<Enter>":
+					if query := prompt.Submit(); query != "" {
+						s := &search{query: termQuery(reposToSearch(), query)}
+						go func() { bc.Search <- s }()
+					}
+					fallthrough
+				case "<Escape>":
+					highlighted = LIST
+					lockAndRender(mainScreen)
+				case "<Resize>":
+					handleResize(mainScreen, prompt)
+					lockAndRender(mainScreen, prompt)
+				default:
+					if prompt.Input(e.ID) {
+						lockAndRender(prompt)
+					}
+				}
+			} else if highlighted == HISTORY {
+				switch e.ID {
+				case "j", "<Down>":
+					picker.ScrollDown()
+				case "k", "<Up>":
+					picker.ScrollUp()
+				case "<Enter>":
+					if len(choices) > 0 {
+						s := choices[picker.SelectedRow]
+						prompt.AddHistory(s.query.Search)
+						go func() { bc.Search <- s }()
+					}
+					fallthrough
+				case "<Escape>", "q", "c", "C":
+					highlighted = LIST
+					lockAndRender(mainScreen)
+					continue
+				case "<Resize>":
+					handleResize(mainScreen, picker)
+				}
+				lockAndRender(mainScreen, picker)
+			} else if highlighted == LIBRARY {
+				i := libraryScreen.Selected()
+				switch e.ID {
+				case "j", "<Down>":
+					libraryScreen.ScrollDown()
+				case "k", "<Up>":
+					libraryScreen.ScrollUp()
+				case "g", "<Home>":
+					libraryScreen.ScrollTop()
+				case "G", "<End>":
+					libraryScreen.ScrollBottom()
+				case "o", "<Enter>":
+					if i >= 0 {
+						go runLibraryAction(bc, openEntry, libraryScreen.Entries[i])
+					}
+				case "b":
+					if i >= 0 {
+						go runLibraryAction(bc, exportEntryBib, libraryScreen.Entries[i])
+					}
+				case "c":
+					if i >= 0 {
+						libraryScreen.OnMessage("verifying " + filepath.Base(libraryScreen.Entries[i].Path))
+						go runLibraryAction(bc, verifyEntry, libraryScreen.Entries[i])
+					}
+				case "x", "<Delete>":
+					if libraryScreen.ConfirmDelete() {
+						go runLibraryAction(bc, deleteEntry, libraryScreen.Entries[i])
+					}
+				case "l", "<Escape>", "<Tab>":
+					highlighted = LIST
+					lockAndRender(mainScreen)
+					continue
+				case "<Resize>":
+					handleResize(mainScreen, libraryScreen)
+				}
+				lockAndRender(libraryScreen)
 			} else if highlighted == MODAL {
 				switch e.ID {
 				case "d", "<Enter>", "<Space>":
-					bc.Download <- "Libgen.lc"
+					if !modal.ConfirmDownload() {
+						lockAndRender(modal)
+						continue
+					}
+					bc.Download <- config.UserConfig.Mirror
 					fallthrough
 				case "<Escape>", "c", "C":
 					highlighted = LIST
@@ -220,8 +448,8 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 			} else { //highlighted ==PAGES
 				pi := mainScreen.PageIndicator
 				switch e.ID {
-				case "<Tab>", "b", "B":
-					toggleHighlight(pi, mainScreen.BookList)
+				case "p", "P", "b", "B", "<Escape>":
+					toggleHighlight(pi, mainScreen)
 					highlighted = LIST
 					if pi.Selected != pi.ActiveTabIndex {
 						pi.ActiveTabIndex = pi.Selected
@@ -242,7 +470,8 @@ func eventLoop(mainScreen *w.MainScreen, bc *BookController, done chan bool) {
 					pi.FocusEnd()
 				case "<Enter>":
 					pi.Selected = pi.ActiveTabIndex
-					// load page
+					page := pi.Selected + 1
+					go func() { mainScreen.UpdatePage <- page }()
 				case "<Resize>":
 					handleResize(mainScreen)
 				}
@@ -283,6 +512,23 @@ func updatePercentage(lw *w.Loading, cstat chan int) {
 }
 
 func main() {
+	setup()
+	if name := flag.Arg(0); name != "" {
+		if err := runCommand(name, flag.Args()[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	if jsonOutput {
+		if err := searchJSON(); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	runTUI()
+}
+
+func runTUI() {
 	if err := ui.Init(); err != nil {
 		log.Fatalf("failed to initialize termui: %v", err)
 	}
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// File is a provider backed by a JSON object of records by ISBN.
+// It works offline, so it's useful to curate metadata by hand and to test enrichment
+type File struct {
+	records map[string]*Record
+}
+
+// OpenFile loads the records of fp. ISBNs are compared as ISBN-13
+func OpenFile(fp string) (*File, error) {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]*Record{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	f := &File{records: make(map[string]*Record, len(raw))}
+	for k, rec := range raw {
+		if isbn, err := book.ParseISBN(k); err == nil {
+			f.records[isbn.ISBN13().String()] = rec
+		}
+	}
+	return f, nil
+}
+
+func (f *File) Name() string {
+	return "file"
+}
+
+func (f *File) Lookup(isbn book.ISBN) (*Record, error) {
+	rec := f.records[isbn.ISBN13().String()]
+	if rec == nil {
+		return nil, ErrNotFound
+	}
+	return rec, nil
+}
@@ -0,0 +1,120 @@
+// Package metadata fills book fields missing in repositories from a secondary source looked up by ISBN
+package metadata
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// ErrNotFound is returned by providers without a record of the ISBN
+var ErrNotFound = errors.New("metadata: isbn not found")
+
+// Record is the metadata of a book by a provider. Empty fields are unknown
+type Record struct {
+	Authors     []string `json:"authors,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Subjects    []string `json:"subjects,omitempty"`
+	Pages       int      `json:"pages,omitempty"`
+	Cover       string   `json:"cover,omitempty"`
+}
+
+// Provider looks up the metadata of a book
+type Provider interface {
+	// Name identifies the provider in book sources
+	Name() string
+	Lookup(isbn book.ISBN) (*Record, error)
+}
+
+// New returns the provider by kind: openlibrary or file. Empty kind disables enrichment
+func New(kind, uri string) (Provider, error) {
+	switch strings.ToLower(kind) {
+	case "":
+		return nil, nil
+	case "openlibrary":
+		p, err := NewOpenLibrary(uri)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "file":
+		p, err := OpenFile(uri)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return nil, errors.New("metadata: unknown provider " + kind)
+}
+
+// families returns the lower case family names of authors, so differently
+// written names of the same people compare equal
+func families(authors []string) []string {
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = strings.ToLower(book.ParseAuthor(a).Family)
+	}
+	return names
+}
+
+// Enrich fills the missing description, publisher, subjects, cover and pages of b
+// and replaces the authors with the provider ones when their family names differ.
+// Filled fields are marked in b.Sources. Books without valid ISBN are left unchanged
+func Enrich(p Provider, b *book.Book) error {
+	if p == nil || b == nil {
+		return nil
+	}
+	var (
+		rec *Record
+		err error
+	)
+	for _, isbn := range b.ISBNs() {
+		if rec, err = p.Lookup(isbn); err != ErrNotFound {
+			break
+		}
+	}
+	if err == ErrNotFound || rec == nil {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if b.Sources == nil {
+		b.Sources = map[string]string{}
+	}
+	mark := func(field string) {
+		b.Sources[field] = p.Name()
+	}
+	if b.Synopsis == "" && rec.Description != "" {
+		b.Synopsis = rec.Description
+		mark("Synopsis")
+	}
+	if b.Publisher == "" && rec.Publisher != "" {
+		b.Publisher = rec.Publisher
+		mark("Publisher")
+	}
+	if len(b.Subjects) == 0 && len(rec.Subjects) > 0 {
+		b.Subjects = rec.Subjects
+		mark("Subjects")
+	}
+	if b.PageCount == 0 && rec.Pages > 0 {
+		b.Pages = strconv.Itoa(rec.Pages)
+		mark("Pages")
+	}
+	if b.CoverURL == nil && b.Cover == nil && rec.Cover != "" {
+		if u, err := url.Parse(rec.Cover); err == nil {
+			b.CoverURL = u
+			mark("CoverURL")
+		}
+	}
+	if len(rec.Authors) > 0 && !reflect.DeepEqual(families(rec.Authors), families(b.Authors())) {
+		b.Author = strings.Join(rec.Authors, "; ")
+		mark("Author")
+	}
+	b.Parse()
+	return nil
+}
@@ -0,0 +1,158 @@
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+func newBook(isbn string) *book.Book {
+	b := book.New()
+	b.Title, b.Author, b.ISBN = "Fundamental Algorithms", "Knuth, Donald", isbn
+	b.Parse()
+	return b
+}
+
+func coverOf(b *book.Book) string {
+	if b.CoverURL == nil {
+		return ""
+	}
+	return b.CoverURL.String()
+}
+
+func TestNewWithoutFile(t *testing.T) {
+	p, err := New("file", "testdata/missing.json")
+	if err == nil {
+		t.Fatal("expected error of missing file")
+	}
+	if p != nil {
+		t.Fatalf("provider = %#v, want nil", p)
+	}
+	// a nil provider leaves books unchanged
+	if err := Enrich(p, newBook("9780201896831")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnrichFromFile(t *testing.T) {
+	p, err := New("file", "testdata/records.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBook("9780201896831")
+	b.Publisher = "Addison Wesley Longman"
+	if err := Enrich(p, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Publisher != "Addison Wesley Longman" {
+		t.Errorf("publisher = %q, repository value must be kept", b.Publisher)
+	}
+	if b.Author != "Knuth, Donald" {
+		t.Errorf("author = %q, same family name must be kept", b.Author)
+	}
+	if b.Synopsis != "The first volume of the series." || b.PageCount != 672 {
+		t.Errorf("synopsis = %q, pages = %d", b.Synopsis, b.PageCount)
+	}
+	if coverOf(b) != "https://covers.openlibrary.org/b/id/42-L.jpg" {
+		t.Errorf("cover = %v", b.CoverURL)
+	}
+	want := map[string]string{
+		"Synopsis": "file", "Subjects": "file", "Pages": "file", "CoverURL": "file",
+	}
+	if !reflect.DeepEqual(b.Sources, want) {
+		t.Errorf("sources = %v, want %v", b.Sources, want)
+	}
+}
+
+func TestEnrichAuthors(t *testing.T) {
+	p, err := New("file", "testdata/records.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ author, want string }{
+		{"Knuth, Donald", "Knuth, Donald"},
+		{"D. Knuth", "D. Knuth"},
+		{"", "Donald E. Knuth"},
+		{"Ronald Graham", "Donald E. Knuth"},
+		{"Knuth, Donald; Graham, Ronald", "Donald E. Knuth"},
+	} {
+		b := newBook("9780201896831")
+		b.Author = c.author
+		if err := Enrich(p, b); err != nil {
+			t.Fatal(err)
+		}
+		if b.Author != c.want {
+			t.Errorf("Enrich author %q = %q, want %q", c.author, b.Author, c.want)
+		}
+	}
+}
+
+func TestEnrichNotFound(t *testing.T) {
+	p, err := New("file", "testdata/records.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBook("9780306406157")
+	if err := Enrich(p, b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Sources) != 0 || b.Author != "Knuth, Donald" {
+		t.Errorf("book changed without record: %v %q", b.Sources, b.Author)
+	}
+}
+
+func TestEnrichFromOpenLibrary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books" || r.URL.Query().Get("jscmd") != "details" {
+			http.NotFound(w, r)
+			return
+		}
+		key := r.URL.Query().Get("bibkeys")
+		if key != "ISBN:9780201896831" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fmt.Fprintf(w, `{%q: {
+			"thumbnail_url": "https://covers.openlibrary.org/b/id/7-S.jpg",
+			"details": {
+				"authors": [{"key": "/authors/OL1A", "name": "Donald E. Knuth"}],
+				"publishers": ["Addison-Wesley"],
+				"description": {"type": "/type/text", "value": "Volume one."},
+				"subjects": ["Algorithms"],
+				"number_of_pages": 672
+			}
+		}}`, key)
+	}))
+	defer srv.Close()
+	p, err := New("openlibrary", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBook("9780201896831")
+	if err := Enrich(p, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Author != "Knuth, Donald" || b.Publisher != "Addison-Wesley" || b.Synopsis != "Volume one." {
+		t.Errorf("book = %q %q %q", b.Author, b.Publisher, b.Synopsis)
+	}
+	if !reflect.DeepEqual(b.Subjects, []string{"Algorithms"}) || b.PageCount != 672 {
+		t.Errorf("subjects = %v, pages = %d", b.Subjects, b.PageCount)
+	}
+	if coverOf(b) != "https://covers.openlibrary.org/b/id/7-L.jpg" {
+		t.Errorf("cover = %v", b.CoverURL)
+	}
+	if b.Sources["Publisher"] != "openlibrary" {
+		t.Errorf("sources = %v", b.Sources)
+	}
+	other := newBook("9780306406157")
+	if err := Enrich(p, other); err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Sources) != 0 {
+		t.Errorf("sources of unknown isbn = %v", other.Sources)
+	}
+}
@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/util"
+)
+
+const (
+	openLibraryURL = "https://openlibrary.org"
+	coversURL      = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+)
+
+// OpenLibrary looks up books in the Open Library books API or any server with the same JSON
+type OpenLibrary struct {
+	base *url.URL
+}
+
+// NewOpenLibrary returns the provider of the server at uri. Empty uri is openlibrary.org
+func NewOpenLibrary(uri string) (*OpenLibrary, error) {
+	if uri == "" {
+		uri = openLibraryURL
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenLibrary{base: u}, nil
+}
+
+func (o *OpenLibrary) Name() string {
+	return "openlibrary"
+}
+
+// olText is a string or a {"type", "value"} object in Open Library records
+type olText string
+
+func (t *olText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = olText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = olText(v.Value)
+	return nil
+}
+
+type olBook struct {
+	Thumbnail string `json:"thumbnail_url"`
+	Details   struct {
+		Authors []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+		Publishers  []string `json:"publishers"`
+		Description olText   `json:"description"`
+		Subjects    []string `json:"subjects"`
+		Pages       int      `json:"number_of_pages"`
+		Covers      []int    `json:"covers"`
+	} `json:"details"`
+}
+
+func (o *OpenLibrary) Lookup(isbn book.ISBN) (*Record, error) {
+	key := "ISBN:" + isbn.String()
+	u := *o.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/books"
+	u.RawQuery = url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"details"}}.Encode()
+	resp, err := util.Fetch(&u, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("metadata: openlibrary status " + resp.Status)
+	}
+	books := map[string]*olBook{}
+	if err := json.NewDecoder(resp.Body).Decode(&books); err != nil {
+		return nil, err
+	}
+	b := books[key]
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	rec := &Record{
+		Description: strings.TrimSpace(string(b.Details.Description)),
+		Subjects:    b.Details.Subjects,
+		Pages:       b.Details.Pages,
+	}
+	for _, a := range b.Details.Authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			rec.Authors = append(rec.Authors, name)
+		}
+	}
+	if len(b.Details.Publishers) > 0 {
+		rec.Publisher = b.Details.Publishers[0]
+	}
+	if len(b.Details.Covers) > 0 && b.Details.Covers[0] > 0 {
+		rec.Cover = fmt.Sprintf(coversURL, b.Details.Covers[0])
+	} else if b.Thumbnail != "" {
+		// thumbnails are the small size of the cover
+		rec.Cover = strings.Replace(b.Thumbnail, "-S.", "-L.", 1)
+	}
+	return rec, nil
+}
@@ -0,0 +1,209 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// Info is the document information dictionary of a PDF file
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+}
+
+var infoKeyRe = regexp.MustCompile(`/(Title|Author|Subject|Keywords)\s*([(<])`)
+var infoRefRe = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+var xmpRe = map[string]*regexp.Regexp{
+	"Title":  regexp.MustCompile(`(?s)<dc:title>.*?<rdf:li[^>]*>(.*?)</rdf:li>`),
+	"Author": regexp.MustCompile(`(?s)<dc:creator>.*?<rdf:li[^>]*>(.*?)</rdf:li>`),
+}
+var isbnRe = regexp.MustCompile(`(?i)ISBN(?:-1[03])?:?\s*((?:97[89][- ]?)?\d{1,5}[- ]?\d{1,7}[- ]?\d{1,7}[- ]?[\dX])`)
+
+// literal reads a PDF literal string starting after its "("
+func literal(data []byte) string {
+	var buf bytes.Buffer
+	depth := 1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '\\':
+			if i+1 >= len(data) {
+				break
+			}
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					j := i
+					for j < len(data) && j < i+3 && data[j] >= '0' && data[j] <= '7' {
+						j++
+					}
+					n, _ := strconv.ParseUint(string(data[i:j]), 8, 8)
+					buf.WriteByte(byte(n))
+					i = j - 1
+				} else {
+					buf.WriteByte(e)
+				}
+			}
+		case '(':
+			depth++
+			buf.WriteByte(c)
+		case ')':
+			if depth--; depth == 0 {
+				return decodeText(buf.Bytes())
+			}
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return decodeText(buf.Bytes())
+}
+
+// hexString reads a PDF hex string starting after its "<"
+func hexString(data []byte) string {
+	end := bytes.IndexByte(data, '>')
+	if end < 0 {
+		return ""
+	}
+	digits := strings.Join(strings.Fields(string(data[:end])), "")
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	raw := make([]byte, len(digits)/2)
+	for i := range raw {
+		n, err := strconv.ParseUint(digits[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return ""
+		}
+		raw[i] = byte(n)
+	}
+	return decodeText(raw)
+}
+
+// decodeText decodes UTF-16BE text strings with BOM. Others are taken as Latin-1
+func decodeText(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff {
+		units := make([]uint16, (len(raw)-2)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(raw[2+2*i:])
+		}
+		return strings.TrimSpace(string(utf16.Decode(units)))
+	}
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// infoDict returns the info dictionary object referenced by the trailer.
+// The last reference wins in incrementally updated files
+func infoDict(data []byte) ([]byte, error) {
+	section := data
+	if i := bytes.LastIndex(data, []byte("trailer")); i >= 0 {
+		section = data[i:]
+	} else if i := bytes.LastIndex(data, []byte("/XRef")); i >= 0 {
+		// cross-reference streams keep the trailer keys in their dictionary
+		if j := bytes.LastIndex(data[:i], []byte("obj")); j >= 0 {
+			section = data[j:]
+		}
+	}
+	refs := infoRefRe.FindAllSubmatch(section, -1)
+	if refs == nil {
+		return nil, nil
+	}
+	ref := refs[len(refs)-1]
+	objRe := regexp.MustCompile(`(?:^|[\r\n\s])` + string(ref[1]) + `\s+` + string(ref[2]) + `\s+obj\b`)
+	locs := objRe.FindAllIndex(data, -1)
+	if locs == nil {
+		if bytes.Contains(data, []byte("/ObjStm")) {
+			return nil, errors.New("pdf: info dictionary is inside a compressed object stream")
+		}
+		return nil, errors.New("pdf: info dictionary object " + string(ref[1]) + " not found")
+	}
+	obj := data[locs[len(locs)-1][1]:]
+	if end := bytes.Index(obj, []byte("endobj")); end >= 0 {
+		obj = obj[:end]
+	}
+	return obj, nil
+}
+
+// ReadInfo reads the info dictionary of a PDF file, falling back to XMP metadata.
+// Info inside compressed object streams isn't read and returns an error
+func ReadInfo(fp string) (*Info, error) {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return nil, errors.New("pdf: " + fp + " is not a PDF file")
+	}
+	dict, err := infoDict(data)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, m := range infoKeyRe.FindAllSubmatchIndex(dict, -1) {
+		key, start := string(dict[m[2]:m[3]]), m[5]
+		var value string
+		if dict[m[4]] == '(' {
+			value = literal(dict[start:])
+		} else {
+			value = hexString(dict[start:])
+		}
+		if value != "" && values[key] == "" {
+			values[key] = value
+		}
+	}
+	for key, re := range xmpRe {
+		if values[key] != "" {
+			continue
+		}
+		if m := re.FindSubmatch(data); m != nil {
+			values[key] = strings.TrimSpace(string(m[1]))
+		}
+	}
+	return &Info{
+		Title: values["Title"], Author: values["Author"],
+		Subject: values["Subject"], Keywords: values["Keywords"],
+	}, nil
+}
+
+// ReadMetadata returns the book described by the info of a PDF file
+func ReadMetadata(fp string) (*book.Book, error) {
+	info, err := ReadInfo(fp)
+	if err != nil {
+		return nil, err
+	}
+	b := book.New()
+	b.Title, b.Author, b.Extension = info.Title, info.Author, "pdf"
+	for _, text := range []string{info.Subject, info.Keywords, info.Title} {
+		if m := isbnRe.FindStringSubmatch(text); m != nil {
+			if isbn, err := book.ParseISBN(m[1]); err == nil {
+				b.ISBN = isbn.String()
+				break
+			}
+		}
+	}
+	b.Parse()
+	return b, nil
+}
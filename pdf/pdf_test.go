@@ -0,0 +1,35 @@
+package pdf
+
+import "testing"
+
+func TestReadInfoSkipsOutline(t *testing.T) {
+	info, err := ReadInfo("testdata/outline.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Info{
+		Title:    "The Art of Computer Programming",
+		Author:   "Knuth",
+		Subject:  "ISBN 978-0-201-89683-1",
+		Keywords: "algorithms",
+	}
+	if *info != want {
+		t.Errorf("ReadInfo = %+v, want %+v", *info, want)
+	}
+}
+
+func TestReadMetadataISBN(t *testing.T) {
+	b, err := ReadMetadata("testdata/outline.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ISBN != "9780201896831" {
+		t.Errorf("ISBN = %q, want 9780201896831", b.ISBN)
+	}
+}
+
+func TestReadInfoInObjectStream(t *testing.T) {
+	if _, err := ReadInfo("testdata/objstm.pdf"); err == nil {
+		t.Error("expected an error for info inside an object stream")
+	}
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 )
 
@@ -10,18 +11,59 @@ type QueryOptions struct {
 	Sort     string
 	SortMode SortMode
 	Search   string
+	// Field is the column to search in. Empty means repository default
+	Field string
 }
 
 func NewQueryOptions(search string) *QueryOptions {
 	return &QueryOptions{Search: search, Page: 1}
 }
 
+// NextSort cycles the sort column through sortable repository columns. After the last one sort is disabled
+func (q *QueryOptions) NextSort(r Repository) {
+	columns := r.Columns()
+	next := 0
+	if q.Sort != "" {
+		next = ColumnIndex(r, q.Sort) + 1
+	}
+	for ; next < len(columns); next++ {
+		if r.SortValue(columns[next]) != "" {
+			q.Sort = columns[next]
+			return
+		}
+	}
+	q.Sort = ""
+}
+
+// ToggleSortMode switches between ASC and DESC
+func (q *QueryOptions) ToggleSortMode() {
+	if q.SortMode == ASC {
+		q.SortMode = DESC
+	} else {
+		q.SortMode = ASC
+	}
+}
+
+// SortString describes the active sort
+func (q *QueryOptions) SortString(r Repository) string {
+	if q.Sort == "" {
+		return ""
+	}
+	return fmt.Sprintf("sort: %s %s", q.Sort, q.SortMode)
+}
+
 func FetchData(r Repository, q *QueryOptions, step FetchStep) (string, error) {
 	u := r.BaseURL()
 	params := &url.Values{}
 	Query(r, params, q.Search)
-	if q.Sort != "" {
-		QuerySort(r, params, q.Sort, q.SortMode)
+	if q.Field != "" {
+		QueryColumn(r, params, q.Field)
+	}
+	if q.Page > 1 {
+		QueryPage(r, params, q.Page)
+	}
+	if q.Sort != "" && r.SortEnabled() {
+		QuerySort(r, params, r.SortValue(q.Sort), q.SortMode)
 	}
 	QueryExtraFields(r, params)
 	u.RawQuery = params.Encode()
@@ -31,4 +73,3 @@ func FetchData(r Repository, q *QueryOptions, step FetchStep) (string, error) {
 	}
 	return content, err
 }
-
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// Column names looked up by filters and match rules. Repositories without them aren't filtered by that criteria
+const (
+	AuthorColumn    = "Author"
+	TitleColumn     = "Title"
+	PublisherColumn = "Publisher"
+	SeriesColumn    = "Series"
+	LanguageColumn  = "Language"
+	ExtensionColumn = "Extension"
+	YearColumn      = "Year"
+	SizeColumn      = "Filesize"
+	PagesColumn     = "Pages"
+)
+
+// Filter hides rows that don't match the user criteria
+type Filter struct {
+	Enabled    bool
+	Language   string
+	Extensions []string
+	YearMin    int
+	YearMax    int
+	SizeMin    int64
+	SizeMax    int64
+}
+
+// Empty returns if filter has no criteria at all
+func (f *Filter) Empty() bool {
+	return f.Language == "" && len(f.Extensions) == 0 &&
+		f.YearMin == 0 && f.YearMax == 0 && f.SizeMin == 0 && f.SizeMax == 0
+}
+
+func (f *Filter) matchLanguage(value string) bool {
+	return f.Language == "" || value == "" || strings.EqualFold(f.Language, value)
+}
+
+func (f *Filter) matchExtension(value string) bool {
+	if len(f.Extensions) == 0 || value == "" {
+		return true
+	}
+	for _, ext := range f.Extensions {
+		if strings.EqualFold(strings.TrimPrefix(ext, "."), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchYear(value string) bool {
+	if (f.YearMin == 0 && f.YearMax == 0) || strings.TrimSpace(value) == "" {
+		return true
+	}
+	year, ok := book.ParseYear(value)
+	if !ok {
+		return false
+	}
+	return (f.YearMin == 0 || year >= f.YearMin) && (f.YearMax == 0 || year <= f.YearMax)
+}
+
+func (f *Filter) matchSize(value string) bool {
+	if (f.SizeMin == 0 && f.SizeMax == 0) || strings.TrimSpace(value) == "" {
+		return true
+	}
+	size, ok := book.ParseBytes(value)
+	if !ok {
+		return false
+	}
+	return (f.SizeMin == 0 || size >= f.SizeMin) && (f.SizeMax == 0 || size <= f.SizeMax)
+}
+
+// Match returns if row pass through all filter criteria
+func (f *Filter) Match(r Repository, b *BookRow) bool {
+	if f == nil || !f.Enabled {
+		return true
+	}
+	return f.matchLanguage(b.Column(r, LanguageColumn)) &&
+		f.matchExtension(b.Column(r, ExtensionColumn)) &&
+		f.matchYear(b.Column(r, YearColumn)) &&
+		f.matchSize(b.Column(r, SizeColumn))
+}
+
+// Apply returns only the rows that match the filter
+func (f *Filter) Apply(r Repository, rows []*BookRow) []*BookRow {
+	if f == nil || !f.Enabled {
+		return rows
+	}
+	filtered := make([]*BookRow, 0, len(rows))
+	for _, row := range rows {
+		if f.Match(r, row) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// String describes the filter criteria
+func (f *Filter) String() string {
+	if f == nil || !f.Enabled || f.Empty() {
+		return ""
+	}
+	criteria := make([]string, 0, 4)
+	if f.Language != "" {
+		criteria = append(criteria, f.Language)
+	}
+	if len(f.Extensions) > 0 {
+		criteria = append(criteria, strings.Join(f.Extensions, "/"))
+	}
+	if f.YearMin != 0 || f.YearMax != 0 {
+		criteria = append(criteria, rangeString(int64(f.YearMin), int64(f.YearMax)))
+	}
+	if f.SizeMin != 0 || f.SizeMax != 0 {
+		criteria = append(criteria, rangeString(f.SizeMin>>20, f.SizeMax>>20)+"MB")
+	}
+	return strings.Join(criteria, " ")
+}
+
+func rangeString(min, max int64) string {
+	s := ""
+	if min != 0 {
+		s = strconv.FormatInt(min, 10)
+	}
+	s += "-"
+	if max != 0 {
+		s += strconv.FormatInt(max, 10)
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package repo
+
+import "testing"
+
+func TestFilterMatchMissingValues(t *testing.T) {
+	f := &Filter{Enabled: true, Language: "English", Extensions: []string{"pdf"}, YearMin: 1990, YearMax: 2000, SizeMin: 1 << 20}
+	tests := []struct {
+		match                          func(string) bool
+		empty, inside, outside, broken string
+	}{
+		{f.matchLanguage, "", "english", "German", ""},
+		{f.matchExtension, "", "PDF", "epub", ""},
+		{f.matchYear, "", "1997", "2005", "unknown"},
+		{f.matchSize, "", "12 Mb", "512 kB", "unknown"},
+	}
+	for i, tt := range tests {
+		if !tt.match(tt.empty) {
+			t.Errorf("%d: empty value filtered out", i)
+		}
+		if !tt.match(tt.inside) {
+			t.Errorf("%d: %q filtered out", i, tt.inside)
+		}
+		if tt.match(tt.outside) {
+			t.Errorf("%d: %q matched", i, tt.outside)
+		}
+		if tt.broken != "" && tt.match(tt.broken) {
+			t.Errorf("%d: %q matched", i, tt.broken)
+		}
+	}
+}
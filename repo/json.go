@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/josecleiton/godownbook/book"
+)
+
+// bookRowJSON is the stable representation of a row. Columns are in repository order
+type bookRowJSON struct {
+	InfoPage string            `json:"info_page,omitempty" yaml:"info_page,omitempty"`
+	Columns  []string          `json:"columns" yaml:"columns"`
+	MD5      string            `json:"md5,omitempty" yaml:"md5,omitempty"`
+	Mirrors  map[string]string `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+}
+
+func (b BookRow) wire() bookRowJSON {
+	bj := bookRowJSON{InfoPage: book.URLString(b.InfoPage), Columns: b.Columns, MD5: b.MD5}
+	if len(b.Mirrors) > 0 {
+		bj.Mirrors = make(map[string]string, len(b.Mirrors))
+		for k, u := range b.Mirrors {
+			bj.Mirrors[k] = book.URLString(u)
+		}
+	}
+	return bj
+}
+
+func (b *BookRow) fromWire(bj bookRowJSON) {
+	b.InfoPage = book.ParseURL(bj.InfoPage)
+	b.Columns = bj.Columns
+	b.MD5 = bj.MD5
+	b.Mirrors = nil
+	for k, s := range bj.Mirrors {
+		if u := book.ParseURL(s); u != nil {
+			if b.Mirrors == nil {
+				b.Mirrors = make(map[string]*url.URL, len(bj.Mirrors))
+			}
+			b.Mirrors[k] = u
+		}
+	}
+}
+
+func (b BookRow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.wire())
+}
+
+func (b *BookRow) UnmarshalJSON(data []byte) error {
+	var bj bookRowJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+	b.fromWire(bj)
+	return nil
+}
+
+func (b BookRow) MarshalYAML() (interface{}, error) {
+	return b.wire(), nil
+}
+
+func (b *BookRow) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var bj bookRowJSON
+	if err := unmarshal(&bj); err != nil {
+		return err
+	}
+	b.fromWire(bj)
+	return nil
+}
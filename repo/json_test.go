@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func sampleRow(t *testing.T) *BookRow {
+	info, err := url.Parse("book/index.php?md5=6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror, err := url.Parse("http://libgen.lc/ads.php?md5=6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &BookRow{
+		InfoPage: info,
+		Columns:  []string{"Donald E. Knuth", "Fundamental Algorithms", "Addison-Wesley", "1997"},
+		MD5:      "6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c",
+		Mirrors:  map[string]*url.URL{"Libgen.lc": mirror},
+	}
+}
+
+func TestBookRowJSONRoundTrip(t *testing.T) {
+	row := sampleRow(t)
+	data, err := json.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got BookRow
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*row, got) {
+		t.Errorf("round trip = %+v, want %+v", got, *row)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["info_page"] != row.InfoPage.String() {
+		t.Errorf("info_page = %v", raw["info_page"])
+	}
+}
+
+func TestBookRowYAMLRoundTrip(t *testing.T) {
+	row := sampleRow(t)
+	data, err := yaml.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got BookRow
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*row, got) {
+		t.Errorf("round trip = %+v, want %+v", got, *row)
+	}
+}
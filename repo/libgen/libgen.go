@@ -34,14 +34,17 @@ const (
 	language
 	filesize
 	extension
+	series
 )
 
 type LibGen struct {
 	queryField      string
+	columnField     string
 	baseURL         *url.URL
 	paginationField string
 	sortEnabled     bool
 	sortField       string
+	sortValues      map[string]string
 	sortModeField   string
 	sortModeValues  map[repo.SortMode]string
 	columns         []string
@@ -58,17 +61,29 @@ func Make() LibGen {
 	base, _ := url.Parse("http://gen.lib.rus.ec/search.php")
 	return LibGen{
 		queryField:      "req",
+		columnField:     "column",
 		baseURL:         base,
 		paginationField: "page",
 		sortEnabled:     true,
 		sortField:       "sort",
-		columns:         []string{"Author", "Title", "Publisher", "Year", "Pages", "Language", "Filesize", "Extension"},
+		columns:         []string{"Author", "Title", "Publisher", "Year", "Pages", "Language", "Filesize", "Extension", "Series"},
 		keyColumns:      []int{1, 6, 7, 3},
 		sortModeField:   "sortmode",
 		sortModeValues: map[repo.SortMode]string{
 			repo.ASC:  "ASC",
 			repo.DESC: "DESC",
 		},
+		sortValues: map[string]string{
+			"Author":    "author",
+			"Title":     "title",
+			"Publisher": "publisher",
+			"Year":      "year",
+			"Pages":     "pages",
+			"Language":  "language",
+			"Filesize":  "filesize",
+			"Extension": "extension",
+			"Series":    "series",
+		},
 		extraFields: map[string]string{
 			"phrase": "1",
 			"view":   "simple",
@@ -98,6 +113,14 @@ func (l LibGen) QueryField() string {
 	return l.queryField
 }
 
+func (l LibGen) ColumnField() string {
+	return l.columnField
+}
+
+func (LibGen) IdentifierColumn() string {
+	return "identifier"
+}
+
 func (l LibGen) PaginationField() string {
 	return l.paginationField
 }
@@ -110,6 +133,10 @@ func (l LibGen) SortField() string {
 	return l.sortField
 }
 
+func (l LibGen) SortValue(column string) string {
+	return l.sortValues[column]
+}
+
 func (l LibGen) Columns() []string {
 	return l.columns
 }
@@ -257,6 +284,22 @@ func bookTitleCrawler(node *html.Node) (text, url string, err error) {
 	return "", "", errors.New("book title not found")
 }
 
+// bookSeriesCrawler returns the series linked before the book title
+func bookSeriesCrawler(node *html.Node) string {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if !(child.Type == html.ElementNode && child.Data == "a") {
+			continue
+		}
+		if !strings.Contains(foundAttrib(child, "href"), "column=series") {
+			continue
+		}
+		if txtNode, err := textCrawlerDeep(child); err == nil {
+			return strings.TrimSpace(txtNode.Data)
+		}
+	}
+	return ""
+}
+
 func textCrawler(node *html.Node) (string, error) {
 	for child := node.FirstChild; child != nil; child = child.NextSibling {
 		if child.Type == html.TextNode {
@@ -267,15 +310,21 @@ func textCrawler(node *html.Node) (string, error) {
 }
 
 func newBookRow(tr *html.Node, rowLen int) (*repo.BookRow, error) {
-	br := &repo.BookRow{Columns: make([]string, rowLen)}
+	br := &repo.BookRow{Columns: make([]string, rowLen), Mirrors: map[string]*url.URL{}}
 	i := 0
-	for child := tr.FirstChild; child != nil && i <= extension; child = child.NextSibling {
+	for child := tr.FirstChild; child != nil; child = child.NextSibling {
 		if child.Type == html.ElementNode && child.Data == "td" {
 			// skip ID
 			if i == id {
 				i++
 				continue
 			}
+			// mirrors follow the columns
+			if i > extension {
+				bookRowMirrorCrawler(child, br)
+				i++
+				continue
+			}
 			text := ""
 			switch i {
 			case author:
@@ -287,7 +336,9 @@ func newBookRow(tr *html.Node, rowLen int) (*repo.BookRow, error) {
 				if err != nil {
 					return nil, err
 				}
+				br.MD5 = strings.ToLower(br.InfoPage.Query().Get("md5"))
 				text = t
+				br.Columns[series-1] = bookSeriesCrawler(child)
 			default:
 				t, err := textCrawler(child)
 				if err != nil {
@@ -302,9 +353,24 @@ func newBookRow(tr *html.Node, rowLen int) (*repo.BookRow, error) {
 	return br, nil
 }
 
+// bookRowMirrorCrawler adds the mirror linked in a row cell
+func bookRowMirrorCrawler(node *html.Node, br *repo.BookRow) {
+	a, err := aCrawler(node)
+	if err != nil {
+		return
+	}
+	attribs := attribsToMap(a)
+	href, err := url.Parse(attribs["href"])
+	// relative links are libgen pages, like the librarian edit one
+	if err != nil || attribs["title"] == "" || !href.IsAbs() {
+		return
+	}
+	br.Mirrors[attribs["title"]] = href
+}
+
 func bookRowCrawler(nodes []*html.Node, rowLen int) ([]*repo.BookRow, error) {
 	list := make([]*repo.BookRow, 0, BOOKS_PER_PAGE)
-	for i := 0; i < BOOKS_PER_PAGE; i++ {
+	for i := 0; i < BOOKS_PER_PAGE && i < len(nodes); i++ {
 		br, err := newBookRow(nodes[i], rowLen)
 		if err != nil {
 			return []*repo.BookRow{}, err
@@ -333,7 +399,8 @@ func (l LibGen) GetRows(content string) ([]*repo.BookRow, error) {
 	}
 	trList, err := trListCrawler(tbody, BOOKS_PER_PAGE)
 	if err != nil {
-		return []*repo.BookRow{}, err
+		// the table has only the header
+		return []*repo.BookRow{}, repo.NoRowsError
 	}
 	return bookRowCrawler(trList, len(l.columns))
 }
@@ -400,6 +467,8 @@ func (l LibGen) BookInfo(b *repo.BookRow) (*book.Book, error) {
 		return nil, err
 	}
 	book.URL = &u
+	book.MD5 = b.MD5
+	book.Parse()
 	return book, nil
 }
 
@@ -475,8 +544,9 @@ func bookInfoCrawlerTdCover(node *html.Node, b *book.Book, base *url.URL) error
 	coverUrl := &url.URL{}
 	*coverUrl = *base
 	coverUrl.Path = foundAttrib(img, "src")
-	b.Cover, err = util.FetchImage(coverUrl)
-	return err
+	// cover is fetched lazily by book.LoadCover
+	b.CoverURL = coverUrl
+	return nil
 }
 
 func bookInfoCrawlerTrCover(node *html.Node, b *book.Book, base *url.URL) error {
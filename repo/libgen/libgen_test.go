@@ -0,0 +1,77 @@
+package libgen
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/josecleiton/godownbook/repo"
+)
+
+func readPage(t *testing.T, name string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGetRows(t *testing.T) {
+	l := Make()
+	rows, err := l.GetRows(readPage(t, "search.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	want := map[string]string{
+		repo.AuthorColumn:    "Donald E. Knuth",
+		repo.TitleColumn:     "Fundamental Algorithms",
+		repo.PublisherColumn: "Addison-Wesley",
+		repo.YearColumn:      "1997",
+		repo.PagesColumn:     "672",
+		repo.LanguageColumn:  "English",
+		repo.SizeColumn:      "12 Mb",
+		repo.ExtensionColumn: "djvu",
+		repo.SeriesColumn:    "The Art of Computer Programming",
+	}
+	for column, value := range want {
+		if got := rows[0].Column(l, column); got != value {
+			t.Errorf("column %s = %q, want %q", column, got, value)
+		}
+	}
+	if rows[0].MD5 != "6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c" {
+		t.Errorf("md5 = %q", rows[0].MD5)
+	}
+	if rows[0].InfoPage == nil || rows[0].InfoPage.Path != "book/index.php" {
+		t.Errorf("info page = %v", rows[0].InfoPage)
+	}
+	if len(rows[0].Mirrors) != 2 || rows[0].Mirrors["Libgen.lc"] == nil {
+		t.Errorf("mirrors = %v", rows[0].Mirrors)
+	}
+	if got := rows[1].Column(l, repo.SeriesColumn); got != "" {
+		t.Errorf("series of book without series = %q", got)
+	}
+	if got := rows[1].Column(l, repo.ExtensionColumn); got != "pdf" {
+		t.Errorf("extension = %q, want pdf", got)
+	}
+}
+
+func TestColumnsHaveSeries(t *testing.T) {
+	l := Make()
+	if repo.ColumnIndex(l, repo.SeriesColumn) < 0 {
+		t.Fatal("series column missing, rows can't be grouped by it")
+	}
+	if l.SortValue(repo.SeriesColumn) == "" {
+		t.Error("series column isn't sortable")
+	}
+}
+
+func TestGetRowsWithoutResults(t *testing.T) {
+	page := `<html><body><table></table><table></table>
+<table><tr><td><b>ID</b></td><td><b>Author(s)</b></td></tr></table></body></html>`
+	if _, err := Make().GetRows(page); err != repo.NoRowsError {
+		t.Fatalf("err = %v, want NoRowsError", err)
+	}
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"strings"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/util"
+)
+
+// Criteria used by MatchRules.Prefer
+const (
+	PreferExtension = "extension"
+	PreferLanguage  = "language"
+	PreferYear      = "year"
+	PreferSize      = "size"
+)
+
+// MatchRules picks the best row when there is nobody to choose one
+type MatchRules struct {
+	// Extensions in order of preference
+	Extensions []string
+	Language   string
+	// Prefer criteria in order of importance
+	Prefer []string
+}
+
+func (m *MatchRules) extensionRank(ext string) int {
+	for i, e := range m.Extensions {
+		if strings.EqualFold(strings.TrimPrefix(e, "."), ext) {
+			return i
+		}
+	}
+	return len(m.Extensions)
+}
+
+// compare returns < 0 if a is better than b, > 0 if b is better and 0 if they are tied
+func (m *MatchRules) compare(r Repository, a, b *BookRow) int {
+	for _, criteria := range m.Prefer {
+		diff := 0
+		switch strings.ToLower(criteria) {
+		case PreferExtension:
+			diff = m.extensionRank(a.Column(r, ExtensionColumn)) - m.extensionRank(b.Column(r, ExtensionColumn))
+		case PreferLanguage:
+			if m.Language != "" {
+				diff = languageRank(m.Language, a.Column(r, LanguageColumn)) - languageRank(m.Language, b.Column(r, LanguageColumn))
+			}
+		case PreferYear:
+			// newest first
+			diff = yearOf(r, b) - yearOf(r, a)
+		case PreferSize:
+			// smallest first
+			sa, sb := sizeOf(r, a), sizeOf(r, b)
+			if sa < sb {
+				diff = -1
+			} else if sa > sb {
+				diff = 1
+			}
+		}
+		if diff != 0 {
+			return diff
+		}
+	}
+	return 0
+}
+
+func languageRank(want, lang string) int {
+	if strings.EqualFold(want, lang) {
+		return 0
+	}
+	return 1
+}
+
+func yearOf(r Repository, b *BookRow) int {
+	year, _ := book.ParseYear(b.Column(r, YearColumn))
+	return year
+}
+
+func sizeOf(r Repository, b *BookRow) int64 {
+	size, err := util.ParseSize(b.Column(r, SizeColumn))
+	if err != nil {
+		// unknown sizes are the worst
+		return 1<<63 - 1
+	}
+	return size
+}
+
+// Best returns the best row and the other rows tied with it
+func (m *MatchRules) Best(r Repository, rows []*BookRow) (best *BookRow, ties []*BookRow) {
+	for _, row := range rows {
+		if best == nil {
+			best = row
+			continue
+		}
+		switch diff := m.compare(r, row, best); {
+		case diff < 0:
+			best = row
+			ties = nil
+		case diff == 0:
+			ties = append(ties, row)
+		}
+	}
+	return
+}
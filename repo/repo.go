@@ -22,6 +22,21 @@ const (
 	DESC
 )
 
+func (m SortMode) String() string {
+	if m == DESC {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// ParseSortMode returns the sort mode from its name. Defaults to ASC
+func ParseSortMode(s string) SortMode {
+	if strings.EqualFold(s, "DESC") {
+		return DESC
+	}
+	return ASC
+}
+
 const (
 	RowStep FetchStep = iota
 	InfoPageStep
@@ -36,10 +51,50 @@ type Downloader interface {
 // ContentError generic error on parsing content
 var ContentError = errors.New("content parsing error")
 
+// NoRowsError is returned by GetRows when the page has no books, like a search without results
+var NoRowsError = errors.New("no rows found")
+
 // BookRow represents a book row
 type BookRow struct {
 	InfoPage *url.URL
 	Columns  []string
+	// MD5 of the book file, if repository shows it
+	MD5 string
+	// Mirrors shown in the row by name, if any
+	Mirrors map[string]*url.URL
+}
+
+// Book returns a book with the row values. It's used when the info page isn't available
+func (b BookRow) Book(r Repository) *book.Book {
+	bk := book.New()
+	bk.Author = b.Column(r, AuthorColumn)
+	bk.Title = b.Column(r, TitleColumn)
+	bk.Publisher = b.Column(r, PublisherColumn)
+	bk.Series = b.Column(r, SeriesColumn)
+	bk.Year = b.Column(r, YearColumn)
+	bk.Pages = b.Column(r, PagesColumn)
+	bk.Language = b.Column(r, LanguageColumn)
+	bk.Size = b.Column(r, SizeColumn)
+	bk.Extension = b.Column(r, ExtensionColumn)
+	bk.MD5 = b.MD5
+	bk.URL = b.InfoPage
+	for name, u := range b.Mirrors {
+		bk.Mirrors[name] = u
+	}
+	bk.Parse()
+	return bk
+}
+
+// BookOf returns the book of a row with the info page values over the row ones.
+// When the info page fails, the book has only the row values and the error is returned too
+func BookOf(r Repository, row *BookRow) (*book.Book, error) {
+	b := row.Book(r)
+	info, err := r.BookInfo(row)
+	if err != nil {
+		return b, err
+	}
+	b.Merge(info)
+	return b, nil
 }
 
 func (b BookRow) Key(r Repository, del byte) (key string) {
@@ -52,6 +107,25 @@ func (b BookRow) Key(r Repository, del byte) (key string) {
 	return
 }
 
+// ColumnIndex returns the index of a column by its name or -1 if repository doesn't have it
+func ColumnIndex(r Repository, name string) int {
+	for i, column := range r.Columns() {
+		if strings.EqualFold(column, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Column returns the value of a column by its name
+func (b BookRow) Column(r Repository, name string) string {
+	idx := ColumnIndex(r, name)
+	if idx < 0 || idx >= len(b.Columns) {
+		return ""
+	}
+	return strings.TrimSpace(b.Columns[idx])
+}
+
 // Repository represents a book repository
 type Repository interface {
 	// Key is a string that is unique between repos
@@ -62,12 +136,18 @@ type Repository interface {
 	BaseURL() url.URL
 	// QueryField returns the query field of repository. Ex: ?search=value
 	QueryField() string
+	// ColumnField returns the field param to search in a specific column. Ex: ?column=author
+	ColumnField() string
+	// IdentifierColumn returns the column to search by ISBN, DOI and similar. Ex: ?column=identifier
+	IdentifierColumn() string
 	//  PaginationField returns the page field of repository. Ex: ?page=2
 	PaginationField() string
 	// SortEnabled returns if repository allow sorting
 	SortEnabled() bool
 	// SortField returns the sort field param of repository. Ex: ?sort=author
 	SortField() string
+	// SortValue returns the sort field value to sort by column. Ex: Author -> author
+	SortValue(column string) string
 	// Colums columns from repo
 	Columns() []string
 	// KeyColumn index of main column
@@ -80,7 +160,7 @@ type Repository interface {
 	ExtraFields() map[string]string
 	// ContentType content type of repository. Highly recommended in POST calls
 	ContentType() string
-	// GetRows return rows from content
+	// GetRows return rows from content. Pages without books return NoRowsError
 	GetRows(content string) ([]*BookRow, error)
 	// BookInfo returns a book from row
 	BookInfo(*BookRow) (*book.Book, error)
@@ -102,6 +182,13 @@ func Query(r Repository, params *url.Values, value string) {
 	params.Add(r.QueryField(), value)
 }
 
+// QueryColumn appends the column to search in to url params
+func QueryColumn(r Repository, params *url.Values, column string) {
+	if field := r.ColumnField(); field != "" {
+		params.Add(field, column)
+	}
+}
+
 // QuerySort appends sort field and sort modifier to url params
 func QuerySort(r Repository, params *url.Values, value string, mode SortMode) {
 	params.Add(r.SortField(), value)
@@ -110,10 +197,12 @@ func QuerySort(r Repository, params *url.Values, value string, mode SortMode) {
 	}
 }
 
-// QueryExtraFields appends any extra fields to url params
+// QueryExtraFields appends any extra fields to url params. Params already set are kept
 func QueryExtraFields(r Repository, params *url.Values) {
 	for k, v := range r.ExtraFields() {
-		params.Add(k, v)
+		if params.Get(k) == "" {
+			params.Add(k, v)
+		}
 	}
 }
 
@@ -127,4 +216,3 @@ func FetchContent(r Repository, url *url.URL, step FetchStep) (content string, c
 	resp.Body.Close()
 	return string(body), resp.StatusCode, err
 }
-
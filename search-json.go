@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/repo"
+)
+
+// printJSON writes v indented to stdout
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// searchJSON prints the books of the first result page of the search instead of opening the TUI
+func searchJSON() error {
+	if searchPattern == "" {
+		return errors.New("json: a search is required. Ex: -s \"title\" -json")
+	}
+	r := reposToSearch()
+	rows, _, err := fetchBookRows(r, searchQuery, repo.RowStep)
+	if err != nil && err != repo.NoRowsError {
+		return err
+	}
+	books := []*book.Book{}
+	for _, row := range searchFilter.Apply(r, rows) {
+		books = append(books, row.Book(r))
+	}
+	return printJSON(books)
+}
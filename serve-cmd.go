@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/library"
+	"github.com/josecleiton/godownbook/repo"
+	"github.com/josecleiton/godownbook/util"
+)
+
+// Download status of the API queue
+const (
+	downloadQueued  = "queued"
+	downloadRunning = "downloading"
+	downloadDone    = "done"
+	downloadFailed  = "failed"
+)
+
+// apiDownload is a book in the download queue of the API
+type apiDownload struct {
+	ID       int     `json:"id"`
+	MD5      string  `json:"md5"`
+	Title    string  `json:"title"`
+	Mirror   string  `json:"mirror"`
+	Status   string  `json:"status"`
+	Progress float64 `json:"progress"`
+	Path     string  `json:"path,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	row      *repo.BookRow
+}
+
+// apiResult is a search row as book. Owned is the path of a previous download
+type apiResult struct {
+	Owned string     `json:"owned,omitempty"`
+	Book  *book.Book `json:"book"`
+}
+
+type apiSearch struct {
+	Page    int         `json:"page"`
+	MaxPage int         `json:"max_page"`
+	Results []apiResult `json:"results"`
+}
+
+// maxCachedRows is how many search rows apiServer keeps for book and download requests
+const maxCachedRows = 1000
+
+// apiServer serves the search, info and download of a repository as JSON
+type apiServer struct {
+	r  repo.Repository
+	mu sync.Mutex
+	// rows found by searches by md5, so books can be looked up and downloaded later.
+	// rowOrder keeps the md5s oldest first to drop them past maxCachedRows
+	rows      map[string]*repo.BookRow
+	rowOrder  []string
+	downloads []*apiDownload
+	jobs      chan *apiDownload
+}
+
+func newAPIServer(r repo.Repository) *apiServer {
+	s := &apiServer{r: r, rows: map[string]*repo.BookRow{}, jobs: make(chan *apiDownload, 100)}
+	go s.downloadWorker()
+	return s
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/openapi.json", s.openAPI)
+	mux.HandleFunc("/api/search", s.search)
+	mux.HandleFunc("/api/books/", s.bookInfo)
+	mux.HandleFunc("/api/downloads", s.downloadList)
+	mux.HandleFunc("/api/downloads/", s.downloadStatus)
+	mux.HandleFunc("/api/library", s.libraryList)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// allow replies 405 when the request method isn't method
+func allow(w http.ResponseWriter, req *http.Request, method string) bool {
+	if req.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return false
+	}
+	return true
+}
+
+func (s *apiServer) openAPI(w http.ResponseWriter, req *http.Request) {
+	if !allow(w, req, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(openAPISpec))
+}
+
+// apiFilter returns the config filter with the criteria of the query string over it
+func apiFilter(values map[string][]string) (*repo.Filter, error) {
+	cfg := config.UserConfig.Filter
+	get := func(k string) string {
+		if v := values[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if v := get("lang"); v != "" {
+		cfg.Language = v
+	}
+	if v := get("ext"); v != "" {
+		cfg.Extensions = strings.Split(v, ",")
+	}
+	for k, dst := range map[string]*int{"year_min": &cfg.YearMin, "year_max": &cfg.YearMax} {
+		if v := get(k); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, errors.New(k + " must be a number")
+			}
+			*dst = n
+		}
+	}
+	for k, dst := range map[string]*string{"size_min": &cfg.SizeMin, "size_max": &cfg.SizeMax} {
+		if v := get(k); v != "" {
+			if _, err := util.ParseSize(v); err != nil {
+				return nil, errors.New(k + " must be a size. Ex: 10MB")
+			}
+			*dst = v
+		}
+	}
+	return newFilter(cfg), nil
+}
+
+func (s *apiServer) search(w http.ResponseWriter, req *http.Request) {
+	if !allow(w, req, http.MethodGet) {
+		return
+	}
+	values := req.URL.Query()
+	term := strings.TrimSpace(values.Get("q"))
+	if term == "" {
+		writeError(w, http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+	query := termQuery(s.r, term)
+	if field := values.Get("field"); field != "" {
+		query.Field = field
+	}
+	if page := values.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("page must be a positive number"))
+			return
+		}
+		query.Page = n
+	}
+	if sort := values.Get("sort"); sort != "" {
+		if !s.r.SortEnabled() || repo.ColumnIndex(s.r, sort) < 0 || s.r.SortValue(sort) == "" {
+			writeError(w, http.StatusBadRequest, errors.New("can't sort by "+sort))
+			return
+		}
+		query.Sort = sort
+		query.SortMode = repo.ParseSortMode(values.Get("order"))
+	}
+	filter, err := apiFilter(values)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res := apiSearch{Page: query.Page, MaxPage: query.Page, Results: []apiResult{}}
+	rows, max, err := fetchBookRows(s.r, query, repo.RowStep)
+	if err == repo.NoRowsError {
+		writeJSON(w, http.StatusOK, res)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	res.MaxPage = max
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range filter.Apply(s.r, rows) {
+		b := row.Book(s.r)
+		if row.MD5 != "" {
+			s.remember(row)
+		}
+		result := apiResult{Book: b}
+		if e := localLibrary.FindBook(b); e != nil {
+			result.Owned = e.Path
+		}
+		res.Results = append(res.Results, result)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// remember caches a search row by md5, dropping the oldest past maxCachedRows.
+// Must be called with s.mu held
+func (s *apiServer) remember(row *repo.BookRow) {
+	md5 := strings.ToLower(row.MD5)
+	if _, ok := s.rows[md5]; !ok {
+		s.rowOrder = append(s.rowOrder, md5)
+	}
+	s.rows[md5] = row
+	for len(s.rowOrder) > maxCachedRows {
+		delete(s.rows, s.rowOrder[0])
+		s.rowOrder = s.rowOrder[1:]
+	}
+}
+
+// row returns the row of a previous search by md5
+func (s *apiServer) row(md5 string) *repo.BookRow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rows[strings.ToLower(md5)]
+}
+
+func (s *apiServer) bookInfo(w http.ResponseWriter, req *http.Request) {
+	if !allow(w, req, http.MethodGet) {
+		return
+	}
+	md5 := strings.TrimPrefix(req.URL.Path, "/api/books/")
+	row := s.row(md5)
+	if row == nil {
+		writeError(w, http.StatusNotFound, errors.New("book "+md5+" not found. Search it first"))
+		return
+	}
+	b, err := bookOf(s.r, row)
+	if err != nil {
+		// the row values are still useful without the info page
+		log.Println(err)
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *apiServer) downloadList(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		list := make([]apiDownload, len(s.downloads))
+		for i, d := range s.downloads {
+			list[i] = *d
+		}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		s.enqueue(w, req)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (s *apiServer) enqueue(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		MD5    string `json:"md5"`
+		Mirror string `json:"mirror"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	row := s.row(body.MD5)
+	if row == nil {
+		writeError(w, http.StatusNotFound, errors.New("book "+body.MD5+" not found. Search it first"))
+		return
+	}
+	if body.Mirror == "" {
+		body.Mirror = config.UserConfig.Mirror
+	}
+	if _, err := s.r.DownloadBook(body.Mirror); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	d := &apiDownload{
+		ID: len(s.downloads) + 1, MD5: row.MD5, Title: row.Column(s.r, repo.TitleColumn),
+		Mirror: body.Mirror, Status: downloadQueued, row: row,
+	}
+	s.downloads = append(s.downloads, d)
+	copied := *d
+	s.mu.Unlock()
+	select {
+	case s.jobs <- d:
+		writeJSON(w, http.StatusAccepted, copied)
+	default:
+		s.finish(d, "", errors.New("download queue is full"))
+		writeError(w, http.StatusServiceUnavailable, errors.New("download queue is full"))
+	}
+}
+
+func (s *apiServer) downloadStatus(w http.ResponseWriter, req *http.Request) {
+	if !allow(w, req, http.MethodGet) {
+		return
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/api/downloads/"))
+	s.mu.Lock()
+	if err != nil || id < 1 || id > len(s.downloads) {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, errors.New("download not found"))
+		return
+	}
+	d := *s.downloads[id-1]
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (s *apiServer) libraryList(w http.ResponseWriter, req *http.Request) {
+	if !allow(w, req, http.MethodGet) {
+		return
+	}
+	entries := localLibrary.Entries()
+	list := []library.Entry{}
+	if q := req.URL.Query().Get("q"); q != "" {
+		for _, i := range localLibrary.Search(q) {
+			list = append(list, entries[i])
+		}
+	} else {
+		list = append(list, entries...)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *apiServer) finish(d *apiDownload, path string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		d.Status, d.Error = downloadFailed, err.Error()
+		return
+	}
+	d.Status, d.Path, d.Progress = downloadDone, path, 1
+}
+
+// downloadWorker downloads one book of the queue at a time, like the TUI does
+func (s *apiServer) downloadWorker() {
+	for d := range s.jobs {
+		s.mu.Lock()
+		d.Status = downloadRunning
+		s.mu.Unlock()
+		b, err := bookOf(s.r, d.row)
+		if err != nil {
+			// the row values are enough to download when info page fails
+			log.Println(err)
+		}
+		downloader, err := s.r.DownloadBook(d.Mirror)
+		if err != nil {
+			s.finish(d, "", err)
+			continue
+		}
+		files := make(chan *os.File, 1)
+		progress := make(chan float64)
+		done := make(chan bool)
+		go func() {
+			for {
+				select {
+				case p := <-progress:
+					s.mu.Lock()
+					d.Progress = p
+					s.mu.Unlock()
+				case <-done:
+					return
+				}
+			}
+		}()
+		path, err := downloadBook(downloader, b, files, progress)
+		close(done)
+		s.finish(d, path, err)
+	}
+}
+
+// serveCmd usage: serve [-addr localhost:8080]
+func serveCmd(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	s := newAPIServer(reposToSearch())
+	log.Printf("serving %s API on %s", repository, *addr)
+	return http.ListenAndServe(*addr, s.handler())
+}
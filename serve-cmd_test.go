@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/library"
+	"github.com/josecleiton/godownbook/repo"
+)
+
+const fakeMD5 = "6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c"
+
+// fakeRepo serves search pages from a local server. The search term picks the page:
+// "none" has no rows, "broken" can't be parsed, "down" fails and anything else has one book
+type fakeRepo struct {
+	base *url.URL
+}
+
+type fakeDownloader struct{}
+
+func (fakeDownloader) Key() string {
+	return "Fake"
+}
+
+func (fakeDownloader) Exec(u *url.URL, dest string, file chan *os.File, progress chan float64) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		file <- nil
+		return nil, err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		file <- nil
+		return nil, err
+	}
+	f.WriteString("book content")
+	progress <- 1
+	file <- f
+	return f, nil
+}
+
+func (fakeRepo) Key() string                              { return "fake" }
+func (fakeRepo) HttpMethod(repo.FetchStep) string         { return http.MethodGet }
+func (r fakeRepo) BaseURL() url.URL                       { return *r.base }
+func (fakeRepo) QueryField() string                       { return "q" }
+func (fakeRepo) ColumnField() string                      { return "column" }
+func (fakeRepo) IdentifierColumn() string                 { return "identifier" }
+func (fakeRepo) PaginationField() string                  { return "page" }
+func (fakeRepo) SortEnabled() bool                        { return true }
+func (fakeRepo) SortField() string                        { return "sort" }
+func (fakeRepo) Columns() []string                        { return []string{"Author", "Title", "Year", "Extension"} }
+func (fakeRepo) KeyColumns() []int                        { return []int{1} }
+func (fakeRepo) SortModeField() string                    { return "mode" }
+func (fakeRepo) SortModeValues() map[repo.SortMode]string { return map[repo.SortMode]string{} }
+func (fakeRepo) ExtraFields() map[string]string           { return map[string]string{} }
+func (fakeRepo) ContentType() string                      { return "" }
+func (fakeRepo) MaxPerPage() int                          { return 25 }
+
+func (fakeRepo) SortValue(column string) string {
+	if column == "Year" {
+		return "year"
+	}
+	return ""
+}
+
+func (fakeRepo) GetRows(content string) ([]*repo.BookRow, error) {
+	switch content {
+	case "none":
+		return []*repo.BookRow{}, repo.NoRowsError
+	case "broken":
+		return []*repo.BookRow{}, repo.ContentError
+	}
+	mirror, _ := url.Parse("http://mirror.test/" + fakeMD5)
+	info, _ := url.Parse("book/index.php?md5=" + fakeMD5)
+	return []*repo.BookRow{{
+		InfoPage: info,
+		Columns:  []string{"Donald E. Knuth", "Fundamental Algorithms", "1997", "epub"},
+		MD5:      fakeMD5,
+		Mirrors:  map[string]*url.URL{"Fake": mirror},
+	}}, nil
+}
+
+func (fakeRepo) BookInfo(row *repo.BookRow) (*book.Book, error) {
+	b := book.New()
+	b.Synopsis = "Volume one."
+	b.MD5 = row.MD5
+	return b, nil
+}
+
+func (fakeRepo) MaxPageNumber(content string) (int, error) {
+	return 3, nil
+}
+
+func (fakeRepo) DownloadBook(mirror string) (repo.Downloader, error) {
+	if mirror != "Fake" {
+		return nil, errors.New("mirror not available")
+	}
+	return fakeDownloader{}, nil
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query().Get("q"); q {
+		case "down":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case "none", "broken":
+			w.Write([]byte(q))
+		default:
+			w.Write([]byte("rows"))
+		}
+	}))
+	base, _ := url.Parse(site.URL + "/search")
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+	config.UserConfig.OutDir = dir
+	config.UserConfig.OutDirBib = dir
+	config.UserConfig.Mirror = "Fake"
+	config.UserConfig.ExportFormats = []string{"bibtex"}
+	repository = "fake"
+	if localLibrary, err = library.Open(filepath.Join(dir, "library.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(newAPIServer(fakeRepo{base: base}).handler())
+	return api, func() {
+		api.Close()
+		site.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func request(t *testing.T, method, u string, body interface{}, out interface{}) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("%s %s content type = %q", method, u, ct)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: %v", method, u, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServeSearch(t *testing.T) {
+	api, done := newTestServer(t)
+	defer done()
+	var res apiSearch
+	if code := request(t, http.MethodGet, api.URL+"/api/search?q=knuth&sort=Year&order=desc", nil, &res); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if res.Page != 1 || res.MaxPage != 3 || len(res.Results) != 1 {
+		t.Fatalf("search = %+v", res)
+	}
+	if b := res.Results[0].Book; b.Title != "Fundamental Algorithms" || b.MD5 != fakeMD5 {
+		t.Errorf("book = %+v", b)
+	}
+	if code := request(t, http.MethodGet, api.URL+"/api/search?q=knuth&ext=pdf", nil, &res); code != http.StatusOK || len(res.Results) != 0 {
+		t.Errorf("filtered search = %d %+v", code, res)
+	}
+	if code := request(t, http.MethodGet, api.URL+"/api/search?q=none", nil, &res); code != http.StatusOK || len(res.Results) != 0 {
+		t.Errorf("search without rows = %d %+v", code, res)
+	}
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"q=knuth&page=0", http.StatusBadRequest},
+		{"q=knuth&sort=Title", http.StatusBadRequest},
+		{"q=knuth&year_min=new", http.StatusBadRequest},
+		{"q=knuth&size_max=big", http.StatusBadRequest},
+		{"q=down", http.StatusBadGateway},
+		{"q=broken", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		var e map[string]string
+		if code := request(t, http.MethodGet, api.URL+"/api/search?"+tt.query, nil, &e); code != tt.code || e["error"] == "" {
+			t.Errorf("search %q = %d %v, want %d with error", tt.query, code, e, tt.code)
+		}
+	}
+	if code := request(t, http.MethodPost, api.URL+"/api/search?q=knuth", nil, nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("POST search = %d", code)
+	}
+}
+
+func TestServeBook(t *testing.T) {
+	api, done := newTestServer(t)
+	defer done()
+	if code := request(t, http.MethodGet, api.URL+"/api/books/"+fakeMD5, nil, nil); code != http.StatusNotFound {
+		t.Errorf("book before search = %d", code)
+	}
+	request(t, http.MethodGet, api.URL+"/api/search?q=knuth", nil, nil)
+	var b book.Book
+	if code := request(t, http.MethodGet, api.URL+"/api/books/"+strings.ToUpper(fakeMD5), nil, &b); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if b.Title != "Fundamental Algorithms" || b.Synopsis != "Volume one." || b.Mirrors["Fake"] == nil {
+		t.Errorf("book = %+v", b)
+	}
+	if code := request(t, http.MethodDelete, api.URL+"/api/books/"+fakeMD5, nil, nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE book = %d", code)
+	}
+}
+
+func TestServeDownload(t *testing.T) {
+	api, done := newTestServer(t)
+	defer done()
+	body := map[string]string{"md5": fakeMD5}
+	if code := request(t, http.MethodPost, api.URL+"/api/downloads", body, nil); code != http.StatusNotFound {
+		t.Errorf("download before search = %d", code)
+	}
+	request(t, http.MethodGet, api.URL+"/api/search?q=knuth", nil, nil)
+	if code := request(t, http.MethodPost, api.URL+"/api/downloads", map[string]string{"md5": fakeMD5, "mirror": "Other"}, nil); code != http.StatusBadRequest {
+		t.Errorf("download from unknown mirror = %d", code)
+	}
+	var d apiDownload
+	if code := request(t, http.MethodPost, api.URL+"/api/downloads", body, &d); code != http.StatusAccepted {
+		t.Fatalf("status = %d", code)
+	}
+	if d.ID != 1 || d.Mirror != "Fake" || d.Title != "Fundamental Algorithms" {
+		t.Errorf("download = %+v", d)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Status != downloadDone && d.Status != downloadFailed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		request(t, http.MethodGet, api.URL+"/api/downloads/1", nil, &d)
+	}
+	if d.Status != downloadDone || d.Progress != 1 {
+		t.Fatalf("download = %+v", d)
+	}
+	if data, err := ioutil.ReadFile(d.Path); err != nil || string(data) != "book content" {
+		t.Errorf("file %s = %q, %v", d.Path, data, err)
+	}
+	var list []apiDownload
+	if code := request(t, http.MethodGet, api.URL+"/api/downloads", nil, &list); code != http.StatusOK || len(list) != 1 {
+		t.Errorf("downloads = %d %+v", code, list)
+	}
+	if code := request(t, http.MethodGet, api.URL+"/api/downloads/2", nil, nil); code != http.StatusNotFound {
+		t.Errorf("missing download = %d", code)
+	}
+	if code := request(t, http.MethodPut, api.URL+"/api/downloads", body, nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT downloads = %d", code)
+	}
+	var entries []library.Entry
+	if code := request(t, http.MethodGet, api.URL+"/api/library", nil, &entries); code != http.StatusOK || len(entries) != 1 {
+		t.Fatalf("library = %d %+v", code, entries)
+	}
+	if entries[0].Path != d.Path {
+		t.Errorf("library path = %s, want %s", entries[0].Path, d.Path)
+	}
+	if code := request(t, http.MethodGet, api.URL+"/api/library?q=algorithms", nil, &entries); code != http.StatusOK || len(entries) != 1 {
+		t.Errorf("library search = %d %+v", code, entries)
+	}
+	if code := request(t, http.MethodGet, api.URL+"/api/library?q=cooking", nil, &entries); code != http.StatusOK || len(entries) != 0 {
+		t.Errorf("library search = %d %+v", code, entries)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	api, done := newTestServer(t)
+	defer done()
+	var spec map[string]interface{}
+	if code := request(t, http.MethodGet, api.URL+"/api/openapi.json", nil, &spec); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, p := range []string{"/api/search", "/api/books/{md5}", "/api/downloads", "/api/downloads/{id}", "/api/library"} {
+		if paths[p] == nil {
+			t.Errorf("path %s not described", p)
+		}
+	}
+}
+
+func TestServeRowCacheBound(t *testing.T) {
+	s := &apiServer{rows: map[string]*repo.BookRow{}}
+	for i := 0; i <= maxCachedRows; i++ {
+		s.remember(&repo.BookRow{MD5: fmt.Sprintf("%032X", i)})
+	}
+	// searching a cached book again doesn't grow the cache
+	s.remember(&repo.BookRow{MD5: fmt.Sprintf("%032x", maxCachedRows)})
+	if len(s.rows) != maxCachedRows || len(s.rowOrder) != maxCachedRows {
+		t.Fatalf("cached %d rows, %d md5s", len(s.rows), len(s.rowOrder))
+	}
+	if s.row(fmt.Sprintf("%032x", 0)) != nil {
+		t.Error("oldest row kept")
+	}
+	if s.row(fmt.Sprintf("%032x", maxCachedRows)) == nil {
+		t.Error("newest row dropped")
+	}
+}
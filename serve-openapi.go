@@ -0,0 +1,181 @@
+package main
+
+// openAPISpec describes the API of the serve command.
+// Books and downloads are looked up by the md5 of a result of an earlier search
+// to the same serve process, since repositories can't be queried by md5
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "godownbook",
+    "description": "Book search, info and download of a godownbook repository",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/api/search": {
+      "get": {
+        "summary": "Search books",
+        "parameters": [
+          {"name": "q", "in": "query", "required": true, "description": "search term, ISBN or DOI", "schema": {"type": "string"}},
+          {"name": "field", "in": "query", "description": "column to search in. Ex: author", "schema": {"type": "string"}},
+          {"name": "page", "in": "query", "schema": {"type": "integer", "minimum": 1, "default": 1}},
+          {"name": "sort", "in": "query", "description": "column to sort by", "schema": {"type": "string"}},
+          {"name": "order", "in": "query", "schema": {"type": "string", "enum": ["asc", "desc"], "default": "asc"}},
+          {"name": "lang", "in": "query", "schema": {"type": "string"}},
+          {"name": "ext", "in": "query", "description": "comma separated extensions. Ex: epub,pdf", "schema": {"type": "string"}},
+          {"name": "year_min", "in": "query", "schema": {"type": "integer"}},
+          {"name": "year_max", "in": "query", "schema": {"type": "integer"}},
+          {"name": "size_min", "in": "query", "description": "human readable size. Ex: 10MB", "schema": {"type": "string"}},
+          {"name": "size_max", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "a page of results", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Search"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/books/{md5}": {
+      "get": {
+        "summary": "Book info of a search result",
+        "description": "md5 must be of a result of an earlier search to this server process; only the latest searched books are kept",
+        "parameters": [{"name": "md5", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "the book", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Book"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/downloads": {
+      "get": {
+        "summary": "List downloads",
+        "responses": {
+          "200": {"description": "downloads in queue order", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Download"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Enqueue the download of a search result",
+        "description": "md5 must be of a result of an earlier search to this server process; only the latest searched books are kept",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["md5"],
+            "properties": {"md5": {"type": "string"}, "mirror": {"type": "string", "description": "defaults to the configured mirror"}}
+          }}}
+        },
+        "responses": {
+          "202": {"description": "queued", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Download"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "503": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/downloads/{id}": {
+      "get": {
+        "summary": "Download status",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "the download", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Download"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/library": {
+      "get": {
+        "summary": "List downloaded books",
+        "parameters": [{"name": "q", "in": "query", "description": "words or ISBN to filter by", "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "library entries", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Entry"}}}}}
+        }
+      }
+    },
+    "/api/openapi.json": {
+      "get": {
+        "summary": "This description",
+        "responses": {"200": {"description": "OpenAPI document"}}
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "Error": {"description": "error", "content": {"application/json": {"schema": {"type": "object", "properties": {"error": {"type": "string"}}}}}}
+    },
+    "schemas": {
+      "Book": {
+        "type": "object",
+        "properties": {
+          "title": {"type": "string"},
+          "id": {"type": "string"},
+          "md5": {"type": "string"},
+          "author": {"type": "string"},
+          "publisher": {"type": "string"},
+          "isbn": {"type": "string"},
+          "year": {"type": "string"},
+          "series": {"type": "string"},
+          "size": {"type": "string"},
+          "extension": {"type": "string"},
+          "edition": {"type": "string"},
+          "volume": {"type": "string"},
+          "url": {"type": "string", "description": "info page"},
+          "language": {"type": "string"},
+          "cover": {"type": "string", "description": "cover URL or file path"},
+          "synopsis": {"type": "string"},
+          "pages": {"type": "string"},
+          "mirrors": {"type": "object", "additionalProperties": {"type": "string"}},
+          "extra": {"type": "object", "additionalProperties": {"type": "string"}},
+          "subjects": {"type": "array", "items": {"type": "string"}},
+          "sources": {"type": "object", "description": "metadata provider of enriched fields", "additionalProperties": {"type": "string"}}
+        }
+      },
+      "Search": {
+        "type": "object",
+        "properties": {
+          "page": {"type": "integer"},
+          "max_page": {"type": "integer"},
+          "results": {"type": "array", "items": {
+            "type": "object",
+            "properties": {"owned": {"type": "string", "description": "path of a previous download"}, "book": {"$ref": "#/components/schemas/Book"}}
+          }}
+        }
+      },
+      "Download": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer"},
+          "md5": {"type": "string"},
+          "title": {"type": "string"},
+          "mirror": {"type": "string"},
+          "status": {"type": "string", "enum": ["queued", "downloading", "done", "failed"]},
+          "progress": {"type": "number", "minimum": 0, "maximum": 1},
+          "path": {"type": "string"},
+          "error": {"type": "string"}
+        }
+      },
+      "Entry": {
+        "type": "object",
+        "properties": {
+          "md5": {"type": "string"},
+          "id": {"type": "string"},
+          "isbn": {"type": "string"},
+          "title": {"type": "string"},
+          "author": {"type": "string"},
+          "publisher": {"type": "string"},
+          "year": {"type": "string"},
+          "series": {"type": "string"},
+          "edition": {"type": "string"},
+          "volume": {"type": "string"},
+          "language": {"type": "string"},
+          "extension": {"type": "string"},
+          "size": {"type": "string"},
+          "pages": {"type": "string"},
+          "url": {"type": "string"},
+          "repository": {"type": "string"},
+          "mirror": {"type": "string"},
+          "time": {"type": "string", "format": "date-time"},
+          "path": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
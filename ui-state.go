@@ -1,12 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	// "syscall"
@@ -14,47 +17,319 @@ import (
 	ui "github.com/gizak/termui/v3"
 	"github.com/josecleiton/godownbook/book"
 	"github.com/josecleiton/godownbook/config"
+	"github.com/josecleiton/godownbook/epub"
+	"github.com/josecleiton/godownbook/export"
+	"github.com/josecleiton/godownbook/library"
+	"github.com/josecleiton/godownbook/metadata"
 	"github.com/josecleiton/godownbook/repo"
+	"github.com/josecleiton/godownbook/util"
 	w "github.com/josecleiton/godownbook/widget"
 )
 
 type BookController struct {
-	Display  chan *w.BookModal
-	Download chan string
+	Display        chan *w.BookModal
+	Download       chan string
+	ToggleFilter   chan bool
+	CycleSort      chan bool
+	ToggleSortMode chan bool
+	CycleGroup     chan bool
+	Search         chan *search
+	CoverLoaded    chan *book.Book
+	Mark           chan *mark
+	DownloadMarked chan bool
+	LibraryDone    chan string
 }
 
 func NewBookController() *BookController {
 	return &BookController{
-		Display:  make(chan *w.BookModal),
-		Download: make(chan string),
+		Display:        make(chan *w.BookModal),
+		Download:       make(chan string),
+		ToggleFilter:   make(chan bool),
+		CycleSort:      make(chan bool),
+		ToggleSortMode: make(chan bool),
+		CycleGroup:     make(chan bool),
+		Search:         make(chan *search),
+		CoverLoaded:    make(chan *book.Book),
+		Mark:           make(chan *mark),
+		DownloadMarked: make(chan bool),
+		LibraryDone:    make(chan string),
 	}
 }
 
+// search is a query requested from the TUI. A nil filter keeps the current one
+type search struct {
+	query  *repo.QueryOptions
+	filter *repo.Filter
+	// repository key to search in, the current one when empty
+	repository string
+}
+
+// results keeps every page fetched for the current search
+type results struct {
+	r      repo.Repository
+	query  *repo.QueryOptions
+	filter *repo.Filter
+	cache  map[int][]*repo.BookRow
+	max    int
+	// rows currently displayed
+	rows []*repo.BookRow
+	// column to group rows in tree view
+	group string
+	// rows marked to download, kept between searches
+	marks *marks
+}
+
+// groupColumns are the columns rows can be grouped by in tree view
+var groupColumns = []string{repo.AuthorColumn, repo.SeriesColumn, repo.PublisherColumn}
+
+func newResults(r repo.Repository, query *repo.QueryOptions, filter *repo.Filter) *results {
+	return &results{
+		r: r, query: query, filter: filter,
+		cache: map[int][]*repo.BookRow{},
+		group: nextGroup(r, ""), marks: newMarks(),
+	}
+}
+
+// nextGroup returns the group column after current that repository has
+func nextGroup(r repo.Repository, current string) string {
+	start := 0
+	for i, c := range groupColumns {
+		if c == current {
+			start = i + 1
+		}
+	}
+	for i := 0; i < len(groupColumns); i++ {
+		c := groupColumns[(start+i)%len(groupColumns)]
+		if repo.ColumnIndex(r, c) >= 0 {
+			return c
+		}
+	}
+	return ""
+}
+
+func newFilter(cfg config.Filter) *repo.Filter {
+	f := &repo.Filter{
+		Language: cfg.Language, Extensions: cfg.Extensions,
+		YearMin: cfg.YearMin, YearMax: cfg.YearMax,
+	}
+	var err error
+	if cfg.SizeMin != "" {
+		if f.SizeMin, err = util.ParseSize(cfg.SizeMin); err != nil {
+			log.Println(err)
+		}
+	}
+	if cfg.SizeMax != "" {
+		if f.SizeMax, err = util.ParseSize(cfg.SizeMax); err != nil {
+			log.Println(err)
+		}
+	}
+	f.Enabled = !f.Empty()
+	return f
+}
+
+// load fetches the page if it isn't cached and display it
+func (rs *results) load(page int) error {
+	if rs.cache[page] == nil {
+		rs.query.Page = page
+		br, max, err := fetchBookRows(rs.r, rs.query, repo.RowStep)
+		if err != nil {
+			return err
+		}
+		rs.cache[page] = br
+		if max > 0 {
+			rs.max = max
+		}
+	}
+	rs.query.Page = page
+	rs.refresh()
+	return nil
+}
+
+// reset drops every cached page and loads the first one again
+func (rs *results) reset() error {
+	rs.cache = map[int][]*repo.BookRow{}
+	return rs.load(1)
+}
+
+// refresh applies filter to the current page
+func (rs *results) refresh() {
+	rs.rows = rs.filter.Apply(rs.r, rs.cache[rs.query.Page])
+}
+
+// mark marks or toggles rows of the current page
+func (rs *results) mark(m *mark) {
+	if m.toggle {
+		if m.from >= 0 && m.from < len(rs.rows) {
+			rs.marks.toggle(rs.rows[m.from])
+		}
+		return
+	}
+	from, to := m.from, m.to
+	if from > to {
+		from, to = to, from
+	}
+	for i := from; i <= to && i < len(rs.rows); i++ {
+		if i >= 0 && !rs.marks.has(rs.rows[i]) {
+			rs.marks.toggle(rs.rows[i])
+		}
+	}
+}
+
+// loaded returns filtered rows from every fetched page in page order
+func (rs *results) loaded() []*repo.BookRow {
+	pages := make([]int, 0, len(rs.cache))
+	for page := range rs.cache {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	rows := []*repo.BookRow{}
+	for _, page := range pages {
+		rows = append(rows, rs.filter.Apply(rs.r, rs.cache[page])...)
+	}
+	return rows
+}
+
+// count returns the number of results. It's an estimate when there is more than one page
+func (rs *results) count() int {
+	if rs.max <= 1 {
+		return len(rs.cache[1])
+	}
+	return rs.max * rs.r.MaxPerPage()
+}
+
+func (rs *results) String() string {
+	info := rs.query.SortString(rs.r)
+	if f := rs.filter.String(); f != "" {
+		hidden := len(rs.cache[rs.query.Page]) - len(rs.rows)
+		info = strings.TrimSpace(fmt.Sprintf("%s filter: %s (%d hidden)", info, f, hidden))
+	}
+	return info
+}
+
 func handleError(err error) {
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
-func makeListData(r repo.Repository, br []*repo.BookRow) []w.BookNode {
+// markIndicator prefixes the rows marked to download
+const markIndicator = "* "
+
+// ownedIndicator prefixes the rows already in the local library
+const ownedIndicator = "✓ "
+
+// ownedRow returns the library entry of a row downloaded before
+func ownedRow(r repo.Repository, row *repo.BookRow) *library.Entry {
+	return localLibrary.FindRow(row.MD5, row.Column(r, repo.TitleColumn), row.Column(r, repo.AuthorColumn))
+}
+
+func makeListData(r repo.Repository, br []*repo.BookRow, marks *marks) []w.BookNode {
 	nodes := make([]w.BookNode, len(br))
 	for i, row := range br {
 		nodes[i].Title = strconv.Itoa(i+1) + ". " + row.Key(r, config.UserConfig.Delimiter[0])
-		if i == 1 {
+		if marks.has(row) {
+			nodes[i].Title = fmt.Sprintf("[%s%s](fg:yellow)", markIndicator, nodes[i].Title)
+		} else if ownedRow(r, row) != nil {
+			nodes[i].Title = fmt.Sprintf("[%s%s](fg:cyan)", ownedIndicator, nodes[i].Title)
+		} else if i == 1 {
 			nodes[i].Title = fmt.Sprintf("[%s](fg:blue)", nodes[i].Title)
 		}
 	}
 	return nodes
 }
 
-func fetchBookRows(r repo.Repository, queryOpts *repo.QueryOptions, step repo.FetchStep) ([]*repo.BookRow, int) {
+// tableColumns returns configured columns that repository has
+func tableColumns(r repo.Repository) []string {
+	columns := make([]string, 0, len(r.Columns()))
+	for _, c := range config.UserConfig.Table.Columns {
+		if idx := repo.ColumnIndex(r, c); idx >= 0 {
+			columns = append(columns, r.Columns()[idx])
+		}
+	}
+	if len(columns) == 0 {
+		return r.Columns()
+	}
+	return columns
+}
+
+func makeTableData(r repo.Repository, br []*repo.BookRow, marks *marks) ([][]string, []int) {
+	columns := tableColumns(r)
+	header := append([]string{"#"}, columns...)
+	widths := make([]int, len(header))
+	widths[0] = 3 + len(markIndicator)
+	for i, c := range columns {
+		widths[i+1] = config.UserConfig.Table.ColumnWidth(c)
+	}
+	rows := make([][]string, len(br)+1)
+	rows[0] = header
+	for i, row := range br {
+		cells := make([]string, len(header))
+		cells[0] = strconv.Itoa(i + 1)
+		if marks.has(row) {
+			cells[0] = markIndicator + cells[0]
+		} else if ownedRow(r, row) != nil {
+			cells[0] = ownedIndicator + cells[0]
+		}
+		for j, c := range columns {
+			cells[j+1] = row.Column(r, c)
+		}
+		rows[i+1] = cells
+	}
+	return rows, widths
+}
+
+// makeTreeData groups rows by column, bigger groups first. Leaf index is the row index
+func makeTreeData(r repo.Repository, br []*repo.BookRow, column string, marks *marks) []w.BookNode {
+	groups := map[string][]w.BookNode{}
+	keys := []string{}
+	for i, row := range br {
+		key := row.Column(r, column)
+		if key == "" {
+			key = "Unknown"
+		}
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		leaf := w.BookNode{Title: row.Key(r, config.UserConfig.Delimiter[0]), Index: i}
+		if marks.has(row) {
+			leaf.Title = markIndicator + leaf.Title
+		} else if ownedRow(r, row) != nil {
+			leaf.Title = ownedIndicator + leaf.Title
+		}
+		groups[key] = append(groups[key], leaf)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return len(groups[keys[i]]) > len(groups[keys[j]])
+	})
+	nodes := make([]w.BookNode, len(keys))
+	for i, key := range keys {
+		nodes[i] = w.BookNode{Title: fmt.Sprintf("%s (%d)", key, len(groups[key])), Childs: groups[key]}
+	}
+	return nodes
+}
+
+func newBookTree(rs *results) *w.BookTree {
+	title := fmt.Sprintf("Grouped by %s (%d pages loaded)", rs.group, len(rs.cache))
+	return w.NewBookTree(title, makeTreeData(rs.r, rs.loaded(), rs.group, rs.marks))
+}
+
+// fetchBookRows fetches a page of rows and the number of pages.
+// The error is repo.NoRowsError when the search has no results
+func fetchBookRows(r repo.Repository, queryOpts *repo.QueryOptions, step repo.FetchStep) ([]*repo.BookRow, int, error) {
 	c, err := repo.FetchData(r, queryOpts, step)
-	handleError(err)
+	if err != nil {
+		return nil, 0, err
+	}
 	br, err := r.GetRows(c)
-	handleError(err)
+	if err != nil {
+		return nil, 0, err
+	}
 	max, err := r.MaxPageNumber(c)
-	handleError(err)
-	return br, max
+	if err != nil {
+		// single page results don't have paginator
+		max = 1
+	}
+	return br, max, nil
 }
 
 func terminalDim() (int, int) {
@@ -64,82 +339,393 @@ func terminalDim() (int, int) {
 	return tw, th
 }
 
-func fetchInitialData(r repo.Repository, load chan int) ([]*repo.BookRow, int) {
-	load <- 33
-	queryOpts := repo.NewQueryOptions(searchPattern)
-	br, max := fetchBookRows(r, queryOpts, repo.RowStep)
-	load <- 66
-	return br, max
+// fetchInitialData loads the first page of search. load may be nil when there's no loading widget
+func fetchInitialData(r repo.Repository, query *repo.QueryOptions, filter *repo.Filter, load chan int) (*results, error) {
+	if load != nil {
+		load <- 33
+	}
+	rs := newResults(r, query, filter)
+	if err := rs.load(1); err != nil {
+		return nil, err
+	}
+	recordSearch(rs)
+	if load != nil {
+		load <- 66
+	}
+	return rs, nil
+}
+
+func updateList(mainScreen *w.MainScreen, rs *results) {
+	mainScreen.StatusBar.OnInfo(rs.String())
+	mainScreen.UpdateList <- w.NewBookList(makeListData(rs.r, rs.rows, rs.marks))
+	mainScreen.UpdateTable <- w.NewBookTable(makeTableData(rs.r, rs.rows, rs.marks))
+	mainScreen.UpdateTree <- newBookTree(rs)
+}
+
+// resetResults fetches the first page again and redraws list and pages
+func resetResults(mainScreen *w.MainScreen, rs *results) {
+	if err := rs.reset(); err != nil {
+		log.Println(err)
+		mainScreen.StatusBar.OnMessage("search failed")
+	}
+	mainScreen.UpdatePages <- w.NewPageIndicator(rs.max)
+	updateList(mainScreen, rs)
 }
 
+// bookPaths returns where book and its .bib are written following the name template.
+// An empty bibPath means .bib must be skipped
+func bookPaths(b *book.Book) (dest, bibPath string, err error) {
+	cfg := config.UserConfig
+	rel := b.ToPath()
+	if cfg.Calibre {
+		rel = filepath.FromSlash(b.CalibrePath(cfg.MaxNameLength))
+	} else if cfg.NameTemplate != "" {
+		rel = filepath.FromSlash(b.PathFromTemplate(cfg.NameTemplate, cfg.MaxNameLength))
+	}
+	full := filepath.Join(cfg.OutDir, rel)
+	if _, err := os.Stat(full); err == nil && cfg.Calibre && cfg.OnCollision == util.CollisionSuffix {
+		// each Calibre book needs its own folder, its metadata.opf and cover.jpg would be replaced
+		full = filepath.Join(util.FreeDir(filepath.Dir(full)), filepath.Base(full))
+	}
+	dest = util.FreePath(full, cfg.OnCollision)
+	if dest == "" {
+		return "", "", errors.New(rel + " already exists, skipped")
+	}
+	// .bib follows the book name, even when a suffix is added
+	if rel, err = filepath.Rel(cfg.OutDir, dest); err != nil {
+		return "", "", err
+	}
+	if !onlyMasterBib() {
+		bibPath = util.FreePath(filepath.Join(cfg.OutDirBib, book.PathBIB(rel)), cfg.OnCollision)
+	}
+	for _, p := range []string{dest, bibPath} {
+		if p == "" {
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return "", "", err
+		}
+	}
+	return dest, bibPath, nil
+}
+
+// downloadBook downloads the book file with its .bib and returns the book path
 func downloadBook(
 	downloader repo.Downloader, b *book.Book,
 	cfile chan *os.File, cprogress chan float64,
-) {
+) (string, error) {
 	mirror := downloader.Key()
-	dest := filepath.Join(config.UserConfig.OutDir, b.ToPath())
+	if b.Mirrors[mirror] == nil {
+		cfile <- nil
+		return "", errors.New("mirror " + mirror + " not available for " + b.Title)
+	}
+	dest, bibPath, err := bookPaths(b)
+	if err != nil {
+		cfile <- nil
+		return "", err
+	}
 	f, err := downloader.Exec(b.Mirrors[mirror], dest, cfile, cprogress)
 	if err != nil {
-		return
+		return "", err
 	}
 	f.Close()
-	bibPath := filepath.Join(config.UserConfig.OutDirBib, b.ToPathBIB())
-	bibFile, err := os.Create(bibPath)
+	// the repository checksum identifies the book even when embedding rewrites the file
+	sum, err := util.FileMD5(f.Name())
 	if err != nil {
-		return
+		log.Println(err)
 	}
-	defer bibFile.Close()
-	if _, err = bibFile.WriteString(b.ToBIB()); err != nil {
-		return
+	if config.UserConfig.EmbedEPUB && strings.EqualFold(filepath.Ext(f.Name()), ".epub") {
+		embedEPUB(b, f.Name())
+	}
+	recordDownload(b, f.Name(), mirror, sum)
+	if config.UserConfig.Calibre {
+		if err := b.WriteCalibreSidecar(filepath.Dir(f.Name())); err != nil {
+			log.Println(err)
+		}
+	}
+	if bibPath, err = writeCitations(b, bibPath); err != nil || bibPath == "" {
+		return f.Name(), err
 	}
 	if userCmd := config.UserConfig.ExecCmd; userCmd != "" {
-		cmd := exec.Command(userCmd, f.Name(), bibFile.Name())
+		cmd := exec.Command(userCmd, f.Name(), bibPath)
 		if err := cmd.Start(); err != nil {
 			log.Fatalln(err)
 		}
 	}
+	return f.Name(), nil
+}
+
+// onlyMasterBib reports whether every citation goes to the master .bib, so books need no .bib path
+func onlyMasterBib() bool {
+	cfg := config.UserConfig
+	if cfg.MasterBib == "" {
+		return false
+	}
+	exporters, err := export.ParseFormats(cfg.ExportFormats)
+	if err != nil {
+		return false
+	}
+	for _, e := range exporters {
+		if e.Key() != "bibtex" {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCitations writes the book citation in every configured format next to bibPath.
+// BibTeX goes to the master .bib when it's configured. Returns the first path written
+func writeCitations(b *book.Book, bibPath string) (string, error) {
+	cfg := config.UserConfig
+	exporters, err := export.ParseFormats(cfg.ExportFormats)
+	if err != nil {
+		return "", err
+	}
+	first := ""
+	written := map[string]bool{}
+	for _, e := range exporters {
+		fp := ""
+		if e.Key() == "bibtex" && cfg.MasterBib != "" {
+			fp, err = appendMasterBib(b)
+		} else if bibPath != "" {
+			base := strings.TrimSuffix(bibPath, filepath.Ext(bibPath))
+			name := base + "." + e.Extension()
+			if written[name] {
+				name = base + "." + e.Key() + "." + e.Extension()
+			}
+			written[name] = true
+			// bibPath is already free, paths derived from it may not be
+			if fp = name; fp != bibPath {
+				fp = util.FreePath(fp, cfg.OnCollision)
+			}
+			if fp != "" {
+				err = writeCitation(e, b, fp)
+			}
+		}
+		if err != nil {
+			return first, err
+		}
+		if fp != "" && first == "" {
+			first = fp
+		}
+	}
+	return first, nil
+}
+
+// appendMasterBib adds the book to the master .bib unless it's already there
+func appendMasterBib(b *book.Book) (string, error) {
+	cfg := config.UserConfig
+	key, added, err := book.AppendBib(cfg.MasterBib, *b, cfg.CitationKey)
+	if err != nil {
+		return "", err
+	}
+	if !added {
+		log.Printf("%s already in %s as %s", b.Title, cfg.MasterBib, key)
+	}
+	return cfg.MasterBib, nil
+}
+
+// writeCitation writes the citations of books to fp
+func writeCitation(e export.Exporter, b *book.Book, fp string) error {
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	if err := e.Write(f, []*book.Book{b}, config.UserConfig.CitationKey); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// embedEPUB writes the book metadata and cover into the downloaded EPUB
+func embedEPUB(b *book.Book, fp string) {
+	if b.Cover == nil && b.CoverURL != nil {
+		if err := b.LoadCover(); err != nil {
+			log.Println(err)
+		}
+	}
+	if err := epub.Embed(fp, b); err != nil {
+		log.Println(err)
+	}
+}
+
+// recordDownload adds a downloaded book to the local library. sum is the md5 of the file as downloaded
+func recordDownload(b *book.Book, fp, mirror, sum string) {
+	if localLibrary == nil {
+		return
+	}
+	if abs, err := filepath.Abs(fp); err == nil {
+		fp = abs
+	}
+	e := library.NewEntry(b, fp, sum, repository, mirror)
+	if checksum, err := util.FileMD5(fp); err != nil {
+		log.Println(err)
+	} else if checksum != sum {
+		e.Checksum = checksum
+	}
+	if err := localLibrary.Add(e); err != nil {
+		log.Println(err)
+	}
+}
+
+// bookOf returns the book of a row enriched by the metadata provider.
+// Like repo.BookOf, the book is returned even when the info page fails
+func bookOf(r repo.Repository, row *repo.BookRow) (*book.Book, error) {
+	b, err := repo.BookOf(r, row)
+	if err := metadata.Enrich(metadataProvider, b); err != nil {
+		log.Println(err)
+	}
+	return b, err
+}
+
+// displayBook sends the modal of rows[i] to be displayed. Returns nil if book info isn't available
+func displayBook(r repo.Repository, bc *BookController, rows []*repo.BookRow, i int) *book.Book {
+	if i < 0 || i >= len(rows) {
+		bc.Display <- nil
+		return nil
+	}
+	b, err := bookOf(r, rows[i])
+	owned := ""
+	if e := localLibrary.FindBook(b); e != nil {
+		owned = e.Path
+	}
+	tw, th := terminalDim()
+	modal := w.NewBookModal(b, owned, config.UserConfig.ShowCover, tw, th)
+	if err != nil {
+		log.Println(err)
+		modal.Warn("book info not available, showing search result")
+	}
+	bc.Display <- modal
+	if config.UserConfig.ShowCover && b.Cover == nil && b.CoverURL != nil {
+		go loadCover(bc, b)
+	}
+	return b
+}
+
+// loadCover fetches the cover without blocking the modal
+func loadCover(bc *BookController, b *book.Book) {
+	if err := b.LoadCover(); err != nil {
+		log.Println(err)
+	}
+	bc.CoverLoaded <- b
 }
 
 func fetchData(r repo.Repository, load chan int, done chan bool) {
 	defer func() { done <- true }()
-	br, max := fetchInitialData(r, load)
-	page := 1
-	cache := make(map[int][]*repo.BookRow, max)
-	cache[page] = br
-	nodes := makeListData(r, br)
+	rs, err := fetchInitialData(r, searchQuery, searchFilter, load)
+	handleError(err)
+	nodes := makeListData(r, rs.rows, rs.marks)
 	time.Sleep(50 * time.Millisecond)
 	tw, th := terminalDim()
 	mainScreen := w.NewMainScreen(
-		w.NewStatusBar(), w.NewBookList(nodes), w.NewPageIndicator(max),
-		tw, th,
+		w.NewStatusBar(), w.NewBookList(nodes),
+		w.NewBookTable(makeTableData(r, rs.rows, rs.marks)), newBookTree(rs),
+		w.NewPageIndicator(rs.max), tw, th,
 	)
+	if config.UserConfig.Table.Default {
+		mainScreen.NextView()
+	}
+	mainScreen.StatusBar.OnInfo(rs.String())
 	load <- LOAD_COMPLETED
 	iDone := make(chan bool)
 	bc := NewBookController()
 	go eventLoop(mainScreen, bc, iDone)
-	var book *book.Book
+	jobs := make(chan *downloadJob, downloadQueueSize)
+	go downloadWorker(jobs, mainScreen)
+	var selected *book.Book
+	// confirmOwned is set after warning that marked rows are already downloaded
+	confirmOwned := false
 	for {
 		select {
 		case <-iDone:
 			return
 		case selectedRow := <-mainScreen.SelectedRow:
-			book, err := r.BookInfo(br[selectedRow])
-			if err != nil {
-				bc.Display <- nil
-				break
+			if b := displayBook(r, bc, rs.rows, selectedRow); b != nil {
+				selected = b
+			}
+		case selectedNode := <-mainScreen.SelectedNode:
+			if b := displayBook(r, bc, rs.loaded(), selectedNode); b != nil {
+				selected = b
 			}
-			tw, th := terminalDim()
-			bc.Display <- w.NewBookModal(book, tw, th)
 		case mirror := <-bc.Download:
-			if downloader, err := r.DownloadBook(mirror); err == nil {
-				log.Println("TESTE2")
+			if selected != nil {
+				mainScreen.StatusBar.OnDownload()
+				jobs <- &downloadJob{r: r, book: selected, mirror: mirror}
+			}
+		case m := <-bc.Mark:
+			confirmOwned = false
+			rs.mark(m)
+			updateList(mainScreen, rs)
+		case <-bc.DownloadMarked:
+			if owned := rs.marks.owned(r); owned > 0 && !confirmOwned {
+				confirmOwned = true
+				mainScreen.StatusBar.OnMessage(fmt.Sprintf("%d marked already downloaded, press D again", owned))
+				updateList(mainScreen, rs)
+				break
+			}
+			confirmOwned = false
+			for _, row := range rs.marks.rows {
 				mainScreen.StatusBar.OnDownload()
-				go downloadBook(downloader, book, mainScreen.DownloadedFile, mainScreen.UpdateDown)
+				jobs <- &downloadJob{r: r, row: row, mirror: config.UserConfig.Mirror}
+			}
+			rs.marks.clear()
+			updateList(mainScreen, rs)
+		case page := <-mainScreen.UpdatePage:
+			if err := rs.load(page); err != nil {
+				log.Println(err)
+				mainScreen.StatusBar.OnMessage(fmt.Sprintf("page %d not loaded", page))
+				break
+			}
+			updateList(mainScreen, rs)
+		case <-bc.ToggleFilter:
+			rs.filter.Enabled = !rs.filter.Enabled
+			rs.refresh()
+			updateList(mainScreen, rs)
+		case s := <-bc.Search:
+			query := s.query.Search
+			mainScreen.StatusBar.OnMessage("searching \"" + query + "\"")
+			updateList(mainScreen, rs)
+			filter := s.filter
+			if filter == nil {
+				filter = rs.filter
+			}
+			sr := r
+			if s.repository != "" && supportedRepositories[s.repository] != nil {
+				sr = supportedRepositories[s.repository]
+			}
+			nrs, err := fetchInitialData(sr, s.query, filter, nil)
+			if err != nil {
+				log.Println(err)
+				mainScreen.StatusBar.OnMessage("no results for \"" + query + "\"")
+				updateList(mainScreen, rs)
+				break
+			}
+			if sr == r {
+				nrs.group = rs.group
+				nrs.marks = rs.marks
+			} else {
+				// like history run, saved searches go back to their repository.
+				// Marks and selection of the other repository are dropped
+				r, repository, selected = sr, s.repository, nil
+			}
+			rs = nrs
+			mainScreen.StatusBar.OnMessage("")
+			mainScreen.UpdatePages <- w.NewPageIndicator(rs.max)
+			updateList(mainScreen, rs)
+		case <-bc.CycleGroup:
+			rs.group = nextGroup(r, rs.group)
+			mainScreen.UpdateTree <- newBookTree(rs)
+		case <-bc.CycleSort:
+			if r.SortEnabled() {
+				rs.query.NextSort(r)
+				resetResults(mainScreen, rs)
 			}
-		case page = <-mainScreen.UpdatePage:
-			if cache[page] != nil {
+		case <-bc.ToggleSortMode:
+			if r.SortEnabled() && rs.query.Sort != "" {
+				rs.query.ToggleSortMode()
+				resetResults(mainScreen, rs)
 			}
-			log.Println(page)
 		}
 	}
 }
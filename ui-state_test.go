@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josecleiton/godownbook/book"
+	"github.com/josecleiton/godownbook/config"
+)
+
+// newTestConfig points the output of downloads and citations to a temporary dir
+func newTestConfig(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "godownbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+	config.UserConfig.OutDir = filepath.Join(dir, "books")
+	config.UserConfig.OutDirBib = filepath.Join(dir, "bib")
+	config.UserConfig.MasterBib = ""
+	config.UserConfig.OnCollision = "suffix"
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestWriteCitationsFreePaths(t *testing.T) {
+	dir, done := newTestConfig(t)
+	defer done()
+	config.UserConfig.ExportFormats = []string{"bibtex,ris"}
+	ris := filepath.Join(dir, "book.ris")
+	if err := ioutil.WriteFile(ris, []byte("another book"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := book.New()
+	b.Title, b.Author = "Fundamental Algorithms", "Donald E. Knuth"
+	if _, err := writeCitations(b, filepath.Join(dir, "book.bib")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(ris); string(data) != "another book" {
+		t.Errorf("existing %s overwritten", ris)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "book (1).ris")); err != nil {
+		t.Error(err)
+	}
+	config.UserConfig.OnCollision = "skip"
+	if _, err := writeCitations(b, filepath.Join(dir, "book.bib")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "book (2).ris")); !os.IsNotExist(err) {
+		t.Error("skipped citation written")
+	}
+}
+
+func TestBookPathsWithMasterBib(t *testing.T) {
+	dir, done := newTestConfig(t)
+	defer done()
+	config.UserConfig.MasterBib = filepath.Join(dir, "library.bib")
+	b := book.New()
+	b.Title, b.Author, b.Extension = "Fundamental Algorithms", "Donald E. Knuth", "pdf"
+	for _, formats := range []string{"bibtex", "bibtex,ris"} {
+		config.UserConfig.ExportFormats = []string{formats}
+		dest, bibPath, err := bookPaths(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dest == "" {
+			t.Errorf("%s: no book path", formats)
+		}
+		if only := formats == "bibtex"; only != (bibPath == "") {
+			t.Errorf("%s: bib path = %q", formats, bibPath)
+		}
+		if _, err := os.Stat(config.UserConfig.OutDirBib); (err == nil) != (bibPath != "") {
+			t.Errorf("%s: bib dir created = %v", formats, err == nil)
+		}
+	}
+}
+
+func TestBookPathsCalibreCollision(t *testing.T) {
+	_, done := newTestConfig(t)
+	defer done()
+	config.UserConfig.Calibre = true
+	b := book.New()
+	b.Title, b.Author, b.Extension, b.ID = "Fundamental Algorithms", "Donald E. Knuth", "pdf", "42"
+	first, _, err := bookPaths(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(first, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := bookPaths(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(second) == filepath.Dir(first) || filepath.Base(second) != filepath.Base(first) {
+		t.Errorf("second book at %s, first at %s", second, first)
+	}
+	if filepath.Dir(filepath.Dir(second)) != filepath.Dir(filepath.Dir(first)) {
+		t.Errorf("second book %s not under the author folder", second)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	_ "image/png"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 )
 
@@ -45,6 +46,20 @@ func FetchImage(url *url.URL) (*image.Image, error) {
 	return &img, nil
 }
 
+// ReadImage decodes the image of a local file
+func ReadImage(fp string) (*image.Image, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
 func FetchHeader(url *url.URL, header string) (string, error) {
 	resp, err := http.Head(url.String())
 	if err != nil {
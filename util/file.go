@@ -0,0 +1,66 @@
+package util
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Collision strategies when a file already exists
+const (
+	CollisionSuffix    = "suffix"
+	CollisionSkip      = "skip"
+	CollisionOverwrite = "overwrite"
+)
+
+// FreePath returns the path to be written according to collision strategy.
+// Empty path means the file exists and must be skipped
+func FreePath(fp string, strategy string) string {
+	if _, err := os.Stat(fp); os.IsNotExist(err) {
+		return fp
+	}
+	switch strategy {
+	case CollisionSkip:
+		return ""
+	case CollisionOverwrite:
+		return fp
+	}
+	return suffixed(fp, filepath.Ext(fp))
+}
+
+// FreeDir returns dir when it doesn't exist, otherwise the first "dir (n)" that doesn't
+func FreeDir(dir string) string {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return dir
+	}
+	return suffixed(dir, "")
+}
+
+// suffixed returns the first "base (n)ext" path that doesn't exist
+func suffixed(fp, ext string) string {
+	base := strings.TrimSuffix(fp, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// FileMD5 returns the hex md5 checksum of a file
+func FileMD5(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sizeRe = regexp.MustCompile(`^([\d.,]+)\s*([kmgt]?)(i?b|bytes?)?$`)
+
+var sizeUnits = map[string]int64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+}
+
+// ParseSize converts a human readable size (Ex: "12 Mb", "512 kB", "300 bytes") to bytes
+func ParseSize(s string) (int64, error) {
+	matches := sizeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if matches == nil {
+		return 0, errors.New("util: invalid size " + strconv.Quote(s))
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(matches[1], ",", "."), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n * float64(sizeUnits[matches[2]])), nil
+}
@@ -29,6 +29,16 @@ func (l *BookList) ScrollMiddle() {
 	l.SelectedRow = l.max / 2
 }
 
+func (l *BookList) Selected() int {
+	return l.SelectedRow
+}
+
+func (l *BookList) Select(row int) {
+	if row >= 0 && row < len(l.Rows) {
+		l.SelectedRow = row
+	}
+}
+
 func (l *BookList) ToggleHighlight() {
 	l.highlighted = !l.highlighted
 	l.drawHighlight()
@@ -2,6 +2,8 @@ package widget
 
 import (
 	"fmt"
+	"image"
+	"strings"
 
 	ui "github.com/gizak/termui/v3"
 	w "github.com/gizak/termui/v3/widgets"
@@ -16,6 +18,13 @@ const (
 type BookModal struct {
 	ui.Grid
 	Data *book.Book
+	// Owned is the path of the book when it was downloaded before
+	Owned     string
+	confirmed bool
+	content   *w.Paragraph
+	bar       *w.Paragraph
+	// cover is nil when book has no cover to show
+	cover ui.Drawable
 }
 
 func newParagraph() *w.Paragraph {
@@ -24,41 +33,100 @@ func newParagraph() *w.Paragraph {
 	return p
 }
 
+// from marks a field filled by a metadata provider
+func from(b *book.Book, field string) string {
+	if src := b.Sources[field]; src != "" {
+		return fmt.Sprintf(" [(%s)](fg:blue)", src)
+	}
+	return ""
+}
+
 func newInfoTxt(b *book.Book) string {
-	return fmt.Sprintf(`Author: %s
-Publisher: %s
+	subjects := ""
+	if len(b.Subjects) > 0 {
+		subjects = fmt.Sprintf("Subjects: %s%s\n", strings.Join(b.Subjects, ", "), from(b, "Subjects"))
+	}
+	return fmt.Sprintf(`Author: %s%s
+Publisher: %s%s
 Year: %s
-Pages: %s
+Pages: %s%s
 Lang: %s
 Filesize: %s
 Ext: %s
+%s
 
-
-%s`, b.Author, b.Publisher,
-		b.Year, b.Pages, b.Language,
-		b.Size, b.Extension, b.Synopsis)
+%s%s`, b.Author, from(b, "Author"), b.Publisher, from(b, "Publisher"),
+		b.Year, b.Pages, from(b, "Pages"), b.Language,
+		b.Size, b.Extension, subjects, b.Synopsis, from(b, "Synopsis"))
 }
 
-func NewBookModal(b *book.Book, tw, th int) *BookModal {
+// NewBookModal makes the modal of a book. If withCover, the cover is shown as soon as SetCover is called.
+// owned is the path of a previous download of the book, if any
+func NewBookModal(b *book.Book, owned string, withCover bool, tw, th int) *BookModal {
 	bm := &BookModal{Data: b}
 	bm.Grid = *ui.NewGrid()
+	bm.content = w.NewParagraph()
+	bm.content.Text = newInfoTxt(b)
+	bm.content.Title = b.Title
+	bm.bar = w.NewParagraph()
+	bm.bar.Text = "Press 'd' to download or 'ESC' to exit"
+	if owned != "" {
+		bm.Owned = owned
+		bm.bar.Text = fmt.Sprintf("[Already downloaded to %s](fg:cyan)\n%s", owned, bm.bar.Text)
+	}
+	if withCover && b.Cover != nil {
+		bm.SetCover(*b.Cover)
+	} else if withCover && b.CoverURL != nil {
+		loading := newParagraph()
+		loading.Text = "loading cover..."
+		bm.cover = loading
+	}
+	bm.layout()
 	bm.Resize(tw, th)
-	content := w.NewParagraph()
-	content.Text = newInfoTxt(b)
-	content.Title = b.Title
-	bar := w.NewParagraph()
-	bar.Text = "Press 'd' to download or 'ESC' to exit"
-	// img := w.NewImage(*b.Cover)
-	// img.SetRect(0, 0, modalw, modalh)
-	bm.Set(ui.NewRow(0.75, content), ui.NewRow(0.25, bar))
-	// content := newContent(b)
-	// content.SetRect(modalw/10, modalh/10, 2*modalw/3, modalh)
-	// bm.Set(ui.NewCol(0.33, img), ui.NewCol(0.77, content))
 	return bm
 }
 
+func (bm *BookModal) layout() {
+	// ui.Grid.Set appends items
+	bm.Items = nil
+	if bm.cover == nil {
+		bm.Set(ui.NewRow(0.75, bm.content), ui.NewRow(0.25, bm.bar))
+		return
+	}
+	bm.Set(
+		ui.NewRow(0.75, ui.NewCol(0.3, bm.cover), ui.NewCol(0.7, bm.content)),
+		ui.NewRow(0.25, bm.bar),
+	)
+}
+
+// SetCover replaces the loading text with the cover. A nil img removes it
+func (bm *BookModal) SetCover(img image.Image) {
+	if img == nil {
+		bm.cover = nil
+	} else {
+		cover := w.NewImage(img)
+		cover.Border = false
+		bm.cover = cover
+	}
+	bm.layout()
+}
+
+// ConfirmDownload returns false the first time a book already owned is downloaded
+func (bm *BookModal) ConfirmDownload() bool {
+	if bm.Owned == "" || bm.confirmed {
+		return true
+	}
+	bm.confirmed = true
+	bm.bar.Text = fmt.Sprintf("[%s is already downloaded. Press 'd' again to download it anyway](fg:yellow)", bm.Owned)
+	return false
+}
+
+// Warn shows msg above the key help
+func (bm *BookModal) Warn(msg string) {
+	bm.bar.Text = fmt.Sprintf("[%s](fg:yellow)\n%s", msg, bm.bar.Text)
+}
+
 func (b *BookModal) Resize(tw, th int) {
 	modalw, modalh := 2*tw/3, 2*th/3
 	b.SetRect(tw/4, th/4, modalw, modalh)
 }
-
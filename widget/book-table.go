@@ -1,24 +1,192 @@
 package widget
 
 import (
+	"image"
+
 	ui "github.com/gizak/termui/v3"
 	w "github.com/gizak/termui/v3/widgets"
 )
 
 type BookTable struct {
 	w.Table
-	i int
+	SelectedRow int
+	// first row is the header
+	rows        [][]string
+	widths      []int
+	topRow      int
+	highlighted bool
 }
 
-func NewBookTable(rows [][]string) *BookTable {
-	table := &BookTable{}
+// NewBookTable makes a table with rows[0] as header. Zero widths share the remaining space
+func NewBookTable(rows [][]string, widths []int) *BookTable {
+	table := &BookTable{rows: rows, widths: widths}
 	table.Table = *w.NewTable()
-	table.Rows = rows
 	table.BorderStyle = ui.NewStyle(ui.ColorGreen)
-	table.TextStyle = ui.NewStyle(ui.ColorClear)
-	table.TextAlignment = ui.AlignCenter
-	table.RowSeparator = true
-	table.FillRow = false
-	table.RowStyles[0] = ui.NewStyle(ui.ColorMagenta, ui.ColorClear, ui.ModifierBold)
+	table.TextStyle = ui.NewStyle(ui.ColorGreen)
+	table.TextAlignment = ui.AlignLeft
+	table.RowSeparator = false
+	table.FillRow = true
+	table.ColumnResizer = table.resizeColumns
 	return table
 }
+
+func (t *BookTable) resizeColumns() {
+	if len(t.rows) == 0 {
+		return
+	}
+	n := len(t.rows[0])
+	// one cell is used by each column separator
+	free := t.Inner.Dx() - n
+	auto := 0
+	for i := 0; i < n; i++ {
+		if i < len(t.widths) && t.widths[i] > 0 {
+			free -= t.widths[i]
+		} else {
+			auto++
+		}
+	}
+	t.ColumnWidths = make([]int, n)
+	for i := range t.ColumnWidths {
+		if i < len(t.widths) && t.widths[i] > 0 {
+			t.ColumnWidths[i] = t.widths[i]
+		} else if free > 0 {
+			t.ColumnWidths[i] = free / auto
+		} else {
+			t.ColumnWidths[i] = 1
+		}
+	}
+}
+
+// visibleRows number of book rows that fit in the table
+func (t *BookTable) visibleRows() int {
+	if n := t.Inner.Dy() - 1; n > 0 {
+		return n
+	}
+	return 1
+}
+
+func (t *BookTable) Draw(buf *ui.Buffer) {
+	if len(t.rows) == 0 {
+		t.Block.Draw(buf)
+		return
+	}
+	books := t.rows[1:]
+	if t.SelectedRow >= t.topRow+t.visibleRows() {
+		t.topRow = t.SelectedRow - t.visibleRows() + 1
+	} else if t.SelectedRow < t.topRow {
+		t.topRow = t.SelectedRow
+	}
+	if t.topRow > len(books) {
+		t.topRow = len(books)
+	}
+	if t.topRow < 0 {
+		t.topRow = 0
+	}
+	end := t.topRow + t.visibleRows()
+	if end > len(books) {
+		end = len(books)
+	}
+	t.Rows = append([][]string{t.rows[0]}, books[t.topRow:end]...)
+	t.RowStyles = map[int]ui.Style{
+		0: ui.NewStyle(ui.ColorMagenta, ui.ColorClear, ui.ModifierBold),
+	}
+	if t.SelectedRow >= t.topRow && t.SelectedRow < end {
+		t.RowStyles[t.SelectedRow-t.topRow+1] = ui.NewStyle(ui.ColorBlack, ui.ColorGreen)
+	}
+	t.Table.Draw(buf)
+	if t.topRow > 0 {
+		buf.SetCell(
+			ui.NewCell(ui.UP_ARROW, ui.NewStyle(ui.ColorWhite)),
+			image.Pt(t.Inner.Max.X-1, t.Inner.Min.Y+1),
+		)
+	}
+	if len(books) > end {
+		buf.SetCell(
+			ui.NewCell(ui.DOWN_ARROW, ui.NewStyle(ui.ColorWhite)),
+			image.Pt(t.Inner.Max.X-1, t.Inner.Max.Y-1),
+		)
+	}
+}
+
+func (t *BookTable) len() int {
+	if len(t.rows) == 0 {
+		return 0
+	}
+	return len(t.rows) - 1
+}
+
+// ScrollAmount scrolls by amount given. If amount is < 0, then scroll up
+func (t *BookTable) ScrollAmount(amount int) {
+	if t.len() == 0 {
+		t.SelectedRow = 0
+	} else if t.len()-t.SelectedRow <= amount {
+		t.SelectedRow = t.len() - 1
+	} else if t.SelectedRow+amount < 0 {
+		t.SelectedRow = 0
+	} else {
+		t.SelectedRow += amount
+	}
+}
+
+func (t *BookTable) ScrollUp() {
+	t.ScrollAmount(-1)
+}
+
+func (t *BookTable) ScrollDown() {
+	t.ScrollAmount(1)
+}
+
+func (t *BookTable) ScrollPageUp() {
+	if t.SelectedRow > t.topRow {
+		t.SelectedRow = t.topRow
+	} else {
+		t.ScrollAmount(-t.visibleRows())
+	}
+}
+
+func (t *BookTable) ScrollPageDown() {
+	t.ScrollAmount(t.visibleRows())
+}
+
+func (t *BookTable) ScrollHalfPageUp() {
+	t.ScrollAmount(-t.visibleRows() / 2)
+}
+
+func (t *BookTable) ScrollHalfPageDown() {
+	t.ScrollAmount(t.visibleRows() / 2)
+}
+
+func (t *BookTable) ScrollTop() {
+	t.SelectedRow = 0
+}
+
+func (t *BookTable) ScrollBottom() {
+	if t.len() == 0 {
+		t.SelectedRow = 0
+		return
+	}
+	t.SelectedRow = t.len() - 1
+}
+
+func (t *BookTable) ScrollMiddle() {
+	t.SelectedRow = t.len() / 2
+}
+
+func (t *BookTable) Selected() int {
+	return t.SelectedRow
+}
+
+func (t *BookTable) Select(row int) {
+	if row >= 0 && row < t.len() {
+		t.SelectedRow = row
+	}
+}
+
+func (t *BookTable) ToggleHighlight() {
+	t.highlighted = !t.highlighted
+	if t.highlighted {
+		t.BorderStyle = ui.NewStyle(ui.ColorBlue)
+	} else {
+		t.BorderStyle = ui.NewStyle(ui.ColorGreen)
+	}
+}
@@ -0,0 +1,44 @@
+package widget
+
+import (
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+func TestBookTableEmptyScroll(t *testing.T) {
+	table := NewBookTable([][]string{{"Title", "Author"}}, nil)
+	table.SetRect(0, 0, 40, 10)
+	buf := ui.NewBuffer(table.GetRect())
+	for _, scroll := range []func(){table.ScrollDown, table.ScrollBottom, table.ScrollPageDown, table.ScrollUp} {
+		scroll()
+		if table.SelectedRow != 0 {
+			t.Fatalf("selected row of empty table = %d, want 0", table.SelectedRow)
+		}
+		table.Draw(buf)
+	}
+}
+
+func TestBookTableScroll(t *testing.T) {
+	rows := [][]string{{"Title"}}
+	for i := 0; i < 20; i++ {
+		rows = append(rows, []string{"book"})
+	}
+	table := NewBookTable(rows, nil)
+	table.SetRect(0, 0, 40, 10)
+	buf := ui.NewBuffer(table.GetRect())
+	table.ScrollBottom()
+	table.Draw(buf)
+	if table.SelectedRow != 19 {
+		t.Fatalf("selected row = %d, want 19", table.SelectedRow)
+	}
+	table.ScrollAmount(100)
+	if table.SelectedRow != 19 {
+		t.Fatalf("selected row = %d, want 19", table.SelectedRow)
+	}
+	table.ScrollAmount(-100)
+	table.Draw(buf)
+	if table.SelectedRow != 0 {
+		t.Fatalf("selected row = %d, want 0", table.SelectedRow)
+	}
+}
@@ -7,28 +7,96 @@ import (
 
 type BookTree struct {
 	w.Tree
-	i int
+	highlighted bool
 }
 
-type nodeValue string
+type nodeValue struct {
+	title string
+	// index of the row represented by a leaf. Groups are -1
+	index int
+}
 
 func (nv nodeValue) String() string {
-	return string(nv)
+	return nv.title
 }
 
-func NewBookTree(nodes []BookNode) *BookTree {
-	tree := &BookTree{}
-	tree.Tree = *w.NewTree()
+func newTreeNodes(nodes []BookNode) []*w.TreeNode {
 	wNodes := make([]*w.TreeNode, len(nodes))
 	for i, node := range nodes {
-		childs := make([]*w.TreeNode, len(node.Childs))
-		wNodes[i] = &w.TreeNode{Value: nodeValue(node.Title), Nodes: childs}
-		for j, v := range node.Childs {
-			childs[j] = &w.TreeNode{Value: nodeValue(v)}
+		index := node.Index
+		if len(node.Childs) > 0 {
+			index = -1
+		}
+		wNodes[i] = &w.TreeNode{
+			Value: nodeValue{title: node.Title, index: index},
+			Nodes: newTreeNodes(node.Childs),
 		}
 	}
+	return wNodes
+}
+
+func NewBookTree(title string, nodes []BookNode) *BookTree {
+	tree := &BookTree{}
+	tree.Tree = *w.NewTree()
+	tree.Title = title
 	tree.TextStyle = ui.NewStyle(ui.ColorGreen)
-	tree.Border = false
-	tree.SetNodes(wNodes)
+	tree.SelectedRowStyle = ui.NewStyle(ui.ColorBlue, ui.ColorClear, ui.ModifierBold)
+	tree.WrapText = false
+	tree.SetNodes(newTreeNodes(nodes))
 	return tree
 }
+
+// SelectedIndex returns the row index of selected leaf. False if a group is selected
+func (t *BookTree) SelectedIndex() (int, bool) {
+	node := t.SelectedNode()
+	if node == nil {
+		return -1, false
+	}
+	nv := node.Value.(nodeValue)
+	return nv.index, nv.index >= 0
+}
+
+func (t *BookTree) ToggleExpand() {
+	if t.SelectedNode() != nil {
+		t.Tree.ToggleExpand()
+	}
+}
+
+func (t *BookTree) Expand() {
+	if t.SelectedNode() != nil {
+		t.Tree.Expand()
+	}
+}
+
+func (t *BookTree) Collapse() {
+	if t.SelectedNode() != nil {
+		t.Tree.Collapse()
+	}
+}
+
+func (t *BookTree) ScrollMiddle() {
+	t.ScrollBottom()
+	t.SelectedRow /= 2
+}
+
+func (t *BookTree) Selected() int {
+	return t.SelectedRow
+}
+
+func (t *BookTree) Select(row int) {
+	selected := t.SelectedRow
+	t.ScrollBottom()
+	if row < 0 || row > t.SelectedRow {
+		row = selected
+	}
+	t.SelectedRow = row
+}
+
+func (t *BookTree) ToggleHighlight() {
+	t.highlighted = !t.highlighted
+	if t.highlighted {
+		t.BorderStyle = ui.NewStyle(ui.ColorBlue)
+	} else {
+		t.BorderStyle = ui.Theme.Block.Border
+	}
+}
@@ -0,0 +1,122 @@
+package widget
+
+import (
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+	"github.com/josecleiton/godownbook/library"
+)
+
+const libraryHelp = "o: open  b: export citations  c: verify checksum  x: delete  TAB/ESC: back to search"
+
+// LibraryScreen lists the books in the local library
+type LibraryScreen struct {
+	ui.Grid
+	Entries []library.Entry
+	list    *w.List
+	details *w.Paragraph
+	bar     *w.Paragraph
+	// entry waiting a second delete key to be removed
+	deleting int
+}
+
+func NewLibraryScreen(entries []library.Entry, tw, th int) *LibraryScreen {
+	ls := &LibraryScreen{list: w.NewList(), details: w.NewParagraph(), bar: w.NewParagraph()}
+	ls.Grid = *ui.NewGrid()
+	ls.list.Title = "Library"
+	ls.list.TextStyle = ui.NewStyle(ui.ColorGreen)
+	ls.list.SelectedRowStyle = ui.NewStyle(ui.ColorBlue, ui.ColorClear, ui.ModifierBold)
+	ls.list.BorderStyle = ui.NewStyle(ui.ColorBlue)
+	ls.list.WrapText = false
+	ls.details.Title = "Details"
+	ls.details.Border = true
+	ls.bar.Text = libraryHelp
+	ls.Set(
+		ui.NewRow(0.9, ui.NewCol(0.5, ls.list), ui.NewCol(0.5, ls.details)),
+		ui.NewRow(0.1, ls.bar),
+	)
+	ls.SetEntries(entries)
+	ls.Resize(tw, th)
+	return ls
+}
+
+// SetEntries replaces the entries keeping the selected row
+func (ls *LibraryScreen) SetEntries(entries []library.Entry) {
+	ls.Entries = entries
+	ls.deleting = -1
+	ls.list.Rows = make([]string, len(entries))
+	for i, e := range entries {
+		ls.list.Rows[i] = e.String()
+	}
+	if ls.list.SelectedRow >= len(entries) {
+		ls.list.SelectedRow = len(entries) - 1
+	}
+	if ls.list.SelectedRow < 0 {
+		ls.list.SelectedRow = 0
+	}
+	ls.updateDetails()
+}
+
+func (ls *LibraryScreen) updateDetails() {
+	if i := ls.Selected(); i >= 0 {
+		ls.details.Text = ls.Entries[i].Details()
+	} else {
+		ls.details.Text = "No books downloaded yet"
+	}
+}
+
+// Selected returns the index of the selected entry or -1 when library is empty
+func (ls *LibraryScreen) Selected() int {
+	if len(ls.Entries) == 0 {
+		return -1
+	}
+	return ls.list.SelectedRow
+}
+
+func (ls *LibraryScreen) ScrollUp() {
+	ls.list.ScrollUp()
+	ls.deleting = -1
+	ls.updateDetails()
+}
+
+func (ls *LibraryScreen) ScrollDown() {
+	ls.list.ScrollDown()
+	ls.deleting = -1
+	ls.updateDetails()
+}
+
+func (ls *LibraryScreen) ScrollTop() {
+	ls.list.ScrollTop()
+	ls.updateDetails()
+}
+
+func (ls *LibraryScreen) ScrollBottom() {
+	ls.list.ScrollBottom()
+	ls.updateDetails()
+}
+
+// ConfirmDelete returns true when delete is pressed twice in the same entry
+func (ls *LibraryScreen) ConfirmDelete() bool {
+	i := ls.Selected()
+	if i < 0 {
+		return false
+	}
+	if ls.deleting == i {
+		ls.deleting = -1
+		return true
+	}
+	ls.deleting = i
+	ls.OnMessage("[Press 'x' again to delete " + ls.Entries[i].Path + "](fg:yellow)")
+	return false
+}
+
+// OnMessage shows msg in place of the key help. Empty msg shows the help again
+func (ls *LibraryScreen) OnMessage(msg string) {
+	if msg == "" {
+		msg = libraryHelp
+	}
+	ls.bar.Text = msg
+}
+
+func (ls *LibraryScreen) Resize(tw, th int) {
+	ls.SetRect(0, 0, tw, th)
+}
@@ -7,24 +7,46 @@ import (
 	// w "github.com/gizak/termui/v3/widgets"
 )
 
+// ViewMode is how results are shown
+type ViewMode int
+
+const (
+	ListView ViewMode = iota
+	TableView
+	TreeView
+)
+
 type MainScreen struct {
 	ui.Grid
 	BookList       *BookList
+	BookTable      *BookTable
+	BookTree       *BookTree
+	Mode           ViewMode
 	PageIndicator  *PageIndicator
 	StatusBar      *StatusBar
 	UpdateList     chan *BookList
+	UpdateTable    chan *BookTable
+	UpdateTree     chan *BookTree
 	UpdatePage     chan int
+	UpdatePages    chan *PageIndicator
 	SelectedRow    chan int
+	SelectedNode   chan int
 	UpdateDown     chan float64
 	DownloadedFile chan *os.File
 }
 
-func NewMainScreen(sb *StatusBar, bl *BookList, pi *PageIndicator, tw, th int) *MainScreen {
+func NewMainScreen(sb *StatusBar, bl *BookList, bt *BookTable, tree *BookTree, pi *PageIndicator, tw, th int) *MainScreen {
 	bl.ToggleHighlight()
+	bt.ToggleHighlight()
+	tree.ToggleHighlight()
 	ms := &MainScreen{
-		StatusBar: sb, BookList: bl, PageIndicator: pi,
+		StatusBar: sb, BookList: bl, BookTable: bt, BookTree: tree, PageIndicator: pi,
 		UpdatePage: make(chan int), UpdateList: make(chan *BookList),
 		UpdateDown: make(chan float64), SelectedRow: make(chan int),
+		UpdatePages:    make(chan *PageIndicator),
+		UpdateTable:    make(chan *BookTable),
+		UpdateTree:     make(chan *BookTree),
+		SelectedNode:   make(chan int),
 		DownloadedFile: make(chan *os.File),
 	}
 	ms.Grid = *ui.NewGrid()
@@ -34,10 +56,78 @@ func NewMainScreen(sb *StatusBar, bl *BookList, pi *PageIndicator, tw, th int) *
 }
 
 func (ms *MainScreen) Update() {
-	ms.Set(ui.NewRow(0.1, ms.StatusBar), ui.NewRow(0.8, ms.BookList), ui.NewRow(0.1, ms.PageIndicator))
+	// ui.Grid.Set appends items
+	ms.Items = nil
+	ms.Set(ui.NewRow(0.1, ms.StatusBar), ui.NewRow(0.8, ms.View()), ui.NewRow(0.1, ms.PageIndicator))
+}
+
+// View returns the widget showing the results
+func (ms *MainScreen) View() BookView {
+	switch ms.Mode {
+	case TableView:
+		return ms.BookTable
+	case TreeView:
+		return ms.BookTree
+	}
+	return ms.BookList
+}
+
+// NextView cycles list, table and tree. List and table keep the selected row
+func (ms *MainScreen) NextView() {
+	selected, from := ms.View().Selected(), ms.Mode
+	ms.Mode = (ms.Mode + 1) % (TreeView + 1)
+	if from != TreeView && ms.Mode != TreeView {
+		ms.View().Select(selected)
+	}
+	ms.Update()
+}
+
+// ToggleHighlight highlights every view to keep them in sync
+func (ms *MainScreen) ToggleHighlight() {
+	ms.BookList.ToggleHighlight()
+	ms.BookTable.ToggleHighlight()
+	ms.BookTree.ToggleHighlight()
+}
+
+// SetBookTree replaces the tree keeping its highlight and selected row
+func (ms *MainScreen) SetBookTree(tree *BookTree) {
+	if ms.BookTree.highlighted != tree.highlighted {
+		tree.ToggleHighlight()
+	}
+	tree.Select(ms.BookTree.Selected())
+	ms.BookTree = tree
+	ms.Update()
+}
+
+// SetBookTable replaces the table keeping its highlight and selected row
+func (ms *MainScreen) SetBookTable(bt *BookTable) {
+	if ms.BookTable.highlighted != bt.highlighted {
+		bt.ToggleHighlight()
+	}
+	bt.Select(ms.BookTable.Selected())
+	ms.BookTable = bt
+	ms.Update()
+}
+
+// SetBookList replaces the list keeping its highlight and selected row
+func (ms *MainScreen) SetBookList(bl *BookList) {
+	if ms.BookList.highlighted != bl.highlighted {
+		bl.ToggleHighlight()
+	}
+	bl.Select(ms.BookList.Selected())
+	ms.BookList = bl
+	ms.Update()
+}
+
+// SetPageIndicator replaces the page indicator keeping its highlight
+func (ms *MainScreen) SetPageIndicator(pi *PageIndicator) {
+	if ms.PageIndicator.highlighted != pi.highlighted {
+		pi.ToggleHighlight()
+	}
+	ms.PageIndicator = pi
+	ms.Update()
 }
 
 func (ms *MainScreen) Resize(tw, th int) {
 	ms.SetRect(0, 0, tw, th)
 }
-
@@ -0,0 +1,27 @@
+package widget
+
+import (
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+)
+
+type SearchPicker struct {
+	w.List
+}
+
+func NewSearchPicker(rows []string, tw, th int) *SearchPicker {
+	sp := &SearchPicker{}
+	sp.List = *w.NewList()
+	sp.Title = "History (Enter to search, ESC to cancel)"
+	sp.Rows = rows
+	sp.TextStyle = ui.NewStyle(ui.ColorGreen)
+	sp.SelectedRowStyle = ui.NewStyle(ui.ColorBlue, ui.ColorClear, ui.ModifierBold)
+	sp.BorderStyle = ui.NewStyle(ui.ColorBlue)
+	sp.WrapText = false
+	sp.Resize(tw, th)
+	return sp
+}
+
+func (sp *SearchPicker) Resize(tw, th int) {
+	sp.SetRect(tw/8, th/8, 7*tw/8, 7*th/8)
+}
@@ -0,0 +1,107 @@
+package widget
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+)
+
+type SearchPrompt struct {
+	w.Paragraph
+	input   []rune
+	history []string
+	// index in history being edited, len(history) means a new query
+	histIdx int
+}
+
+func NewSearchPrompt(tw, th int) *SearchPrompt {
+	sp := &SearchPrompt{}
+	sp.Paragraph = *w.NewParagraph()
+	sp.Title = "Search (ESC to cancel)"
+	sp.BorderStyle = ui.NewStyle(ui.ColorBlue)
+	sp.Resize(tw, th)
+	sp.draw()
+	return sp
+}
+
+// Open clears the input to a new query
+func (sp *SearchPrompt) Open() {
+	sp.input = sp.input[:0]
+	sp.histIdx = len(sp.history)
+	sp.draw()
+}
+
+// Input handles a key event. Returns false if key isn't text
+func (sp *SearchPrompt) Input(key string) bool {
+	switch key {
+	case "<Space>":
+		sp.input = append(sp.input, ' ')
+	case "<Backspace>", "<C-<Backspace>>":
+		if len(sp.input) > 0 {
+			sp.input = sp.input[:len(sp.input)-1]
+		}
+	case "<C-u>":
+		sp.input = sp.input[:0]
+	case "<Up>", "<C-p>":
+		sp.HistoryPrev()
+	case "<Down>", "<C-n>":
+		sp.HistoryNext()
+	default:
+		if utf8.RuneCountInString(key) != 1 {
+			return false
+		}
+		r, _ := utf8.DecodeRuneInString(key)
+		sp.input = append(sp.input, r)
+	}
+	sp.draw()
+	return true
+}
+
+func (sp *SearchPrompt) HistoryPrev() {
+	if sp.histIdx > 0 {
+		sp.histIdx--
+		sp.input = []rune(sp.history[sp.histIdx])
+	}
+}
+
+func (sp *SearchPrompt) HistoryNext() {
+	if sp.histIdx < len(sp.history) {
+		sp.histIdx++
+	}
+	if sp.histIdx == len(sp.history) {
+		sp.input = sp.input[:0]
+	} else {
+		sp.input = []rune(sp.history[sp.histIdx])
+	}
+}
+
+// Submit returns the query and appends it to history
+func (sp *SearchPrompt) Submit() string {
+	query := strings.TrimSpace(string(sp.input))
+	if query != "" {
+		sp.AddHistory(query)
+	}
+	return query
+}
+
+// AddHistory appends query to history, moving it to the end if it's already there
+func (sp *SearchPrompt) AddHistory(query string) {
+	for i, h := range sp.history {
+		if h == query {
+			sp.history = append(sp.history[:i], sp.history[i+1:]...)
+			break
+		}
+	}
+	sp.history = append(sp.history, query)
+	sp.histIdx = len(sp.history)
+}
+
+func (sp *SearchPrompt) draw() {
+	sp.Text = "> " + string(sp.input) + "_"
+}
+
+func (sp *SearchPrompt) Resize(tw, th int) {
+	sp.SetRect(tw/6, th/3, 5*tw/6, th/3+3)
+}
@@ -12,6 +12,7 @@ type StatusBar struct {
 	download      *w.Paragraph
 	title         *w.Paragraph
 	msg           *w.Paragraph
+	info          *w.Paragraph
 	downCount     int
 	finishedCount int
 	progress      int
@@ -21,6 +22,7 @@ func NewStatusBar() *StatusBar {
 	s := &StatusBar{
 		title:    w.NewParagraph(),
 		msg:      w.NewParagraph(),
+		info:     w.NewParagraph(),
 		download: w.NewParagraph(),
 	}
 	s.Grid = *ui.NewGrid()
@@ -28,12 +30,13 @@ func NewStatusBar() *StatusBar {
 	s.title.Border = false
 	s.download.Border = false
 	s.msg.Border = false
+	s.info.Border = false
 	s.Update()
 	return s
 }
 
 func (s *StatusBar) Update() {
-	s.Set(ui.NewCol(0.2, s.title), ui.NewCol(0.6, s.msg), ui.NewCol(0.2, s.download))
+	s.Set(ui.NewCol(0.2, s.title), ui.NewCol(0.4, s.msg), ui.NewCol(0.2, s.info), ui.NewCol(0.2, s.download))
 }
 
 func (s *StatusBar) OnDownload() int {
@@ -58,6 +61,13 @@ func (s *StatusBar) OnMessage(msg string) {
 	s.Unlock()
 }
 
+// OnInfo shows the state of the results (Ex: active filter)
+func (s *StatusBar) OnInfo(info string) {
+	s.Lock()
+	s.info.Text = info
+	s.Unlock()
+}
+
 func (s *StatusBar) OnFinished() int {
 	s.Lock()
 	s.finishedCount++
@@ -1,8 +1,29 @@
 package widget
 
+import ui "github.com/gizak/termui/v3"
+
 type BookNode struct {
-	Title  string
-	Childs []string
+	Title string
+	// Index of the row represented by node. Nodes with childs group rows
+	Index  int
+	Childs []BookNode
+}
+
+// BookView is a widget showing results with a selected row
+type BookView interface {
+	ui.Drawable
+	Highlightable
+	ScrollUp()
+	ScrollDown()
+	ScrollTop()
+	ScrollBottom()
+	ScrollMiddle()
+	ScrollHalfPageUp()
+	ScrollHalfPageDown()
+	ScrollPageUp()
+	ScrollPageDown()
+	Selected() int
+	Select(row int)
 }
 
 type Resizable interface {